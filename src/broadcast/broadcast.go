@@ -0,0 +1,431 @@
+package broadcast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	keyTab    = 9
+	keyEnter  = 13
+	keyPrefix = 29 //ctrl-],starts a broadcast command
+
+	statusRows = 1 //below the panes
+	titleRows  = 1 //above the lines of each pane
+
+	// refresh bounds how often the panes are redrawn while output arrives
+	refresh = 50 * time.Millisecond
+)
+
+// Target is a shell the keystrokes are broadcast to.
+type Target struct {
+	Name   string
+	Input  io.Writer
+	Output io.Reader
+	Resize func(width, height int) error //of its pty
+}
+
+type host struct {
+	*Target
+	screen *screen
+	on     bool //in the broadcast set
+	exited bool
+	// the pane,rows and columns from 0 without the title
+	top, left, width, height int
+	// the size last sent to the pty
+	ptyWidth, ptyHeight int
+}
+
+// Broadcast sends what is typed to every shell in the broadcast set. The
+// shells are shown in panes of plain text or one at a time in the focus view,
+// which passes its output through as is. Commands start with ctrl-]:
+//
+//	f,enter  switch between the panes and the focus view
+//	n,tab,p  select the next or previous host
+//	t,space  toggle the selected host in or out of the broadcast set
+//	a        put all hosts in the broadcast set
+//	o        broadcast to the selected host only
+//	q        quit and close every shell
+//	ctrl-]   send ctrl-] itself
+type Broadcast struct {
+	hosts    []*host
+	selected int
+	focused  bool
+	prefix   bool //ctrl-] was typed,a command follows
+	width    int
+	height   int
+	out      io.Writer
+}
+
+func New(targets []*Target) *Broadcast {
+	b := &Broadcast{hosts: make([]*host, 0, len(targets))}
+	for _, t := range targets {
+		b.hosts = append(b.hosts, &host{Target: t, screen: newScreen(0), on: true})
+	}
+	return b
+}
+
+// output is what a shell printed,nil data once it exited.
+type output struct {
+	index int
+	data  []byte
+}
+
+// Run reads keys from in and shows the shells on out until q is typed,in
+// ends or every shell exited. size reports the terminal size,it is polled so
+// the panes follow a resized terminal.
+func (b *Broadcast) Run(in io.Reader, out io.Writer, size func() (int, int)) error {
+	if len(b.hosts) == 0 {
+		return errors.New("no shell to broadcast to")
+	}
+	b.out = out
+	done := make(chan struct{})
+	defer close(done)
+	outputs := make(chan output)
+	for i, h := range b.hosts {
+		go func(i int, r io.Reader) {
+			for {
+				buf := make([]byte, 4096)
+				n, err := r.Read(buf)
+				if n > 0 {
+					select {
+					case outputs <- output{index: i, data: buf[:n]}:
+					case <-done:
+						return
+					}
+				}
+				if err != nil {
+					select {
+					case outputs <- output{index: i}:
+					case <-done:
+					}
+					return
+				}
+			}
+		}(i, h.Output)
+	}
+	keys := make(chan []byte)
+	go func() {
+		for {
+			buf := make([]byte, 256)
+			n, err := in.Read(buf)
+			if n > 0 {
+				select {
+				case keys <- buf[:n]:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				close(keys)
+				return
+			}
+		}
+	}()
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+	b.layout(size())
+	b.redraw()
+	dirty := false
+	for {
+		select {
+		case o := <-outputs:
+			h := b.hosts[o.index]
+			if o.data == nil {
+				h.exited, h.on = true, false
+				if b.allExited() {
+					return nil
+				}
+				dirty = true
+				continue
+			}
+			h.screen.Write(o.data)
+			if b.focused && o.index == b.selected {
+				out.Write(o.data)
+			} else {
+				dirty = true
+			}
+		case typed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if b.handle(typed) {
+				return nil
+			}
+			dirty = true
+		case <-ticker.C:
+			if width, height := size(); width != b.width || height != b.height {
+				b.layout(width, height)
+				b.redraw()
+				dirty = false
+			}
+			if dirty && !b.focused {
+				b.render()
+			}
+			dirty = false
+		}
+	}
+}
+
+func (b *Broadcast) allExited() bool {
+	for _, h := range b.hosts {
+		if !h.exited {
+			return false
+		}
+	}
+	return true
+}
+
+// handle sends typed to the broadcast set and applies the commands in it,it
+// returns true on quit.
+func (b *Broadcast) handle(typed []byte) bool {
+	for len(typed) > 0 {
+		if b.prefix {
+			b.prefix = false
+			if b.command(typed[0]) {
+				return true
+			}
+			typed = typed[1:]
+			continue
+		}
+		i := bytes.IndexByte(typed, keyPrefix)
+		if i < 0 {
+			b.send(typed)
+			break
+		}
+		b.send(typed[:i])
+		b.prefix = true
+		typed = typed[i+1:]
+	}
+	if !b.focused {
+		// show the commands while ctrl-] waits for one
+		b.render()
+	}
+	return false
+}
+
+func (b *Broadcast) send(keys []byte) {
+	if len(keys) == 0 {
+		return
+	}
+	for _, h := range b.hosts {
+		if h.on && !h.exited {
+			h.Input.Write(keys)
+		}
+	}
+}
+
+func (b *Broadcast) command(key byte) bool {
+	switch key {
+	case 'q':
+		return true
+	case keyPrefix:
+		b.send([]byte{keyPrefix})
+		return false
+	case 'f', keyEnter:
+		b.focused = !b.focused
+		if !b.focused {
+			// a program in the focus view may have left the alternate screen
+			b.out.Write([]byte("\x1b[?1049h"))
+		}
+		b.resize()
+	case 'n', keyTab:
+		b.selected = (b.selected + 1) % len(b.hosts)
+	case 'p':
+		b.selected = (b.selected - 1 + len(b.hosts)) % len(b.hosts)
+	case 't', ' ':
+		if h := b.hosts[b.selected]; !h.exited {
+			h.on = !h.on
+		}
+	case 'a':
+		for _, h := range b.hosts {
+			h.on = !h.exited
+		}
+	case 'o':
+		for i, h := range b.hosts {
+			h.on = i == b.selected && !h.exited
+		}
+	default:
+		return false
+	}
+	b.redraw()
+	return false
+}
+
+// layout places the panes in a grid as square as it gets,one column apart.
+func (b *Broadcast) layout(width, height int) {
+	b.width, b.height = width, height
+	cols := int(math.Ceil(math.Sqrt(float64(len(b.hosts)))))
+	rows := (len(b.hosts) + cols - 1) / cols
+	area := height - statusRows
+	for i, h := range b.hosts {
+		row, col := i/cols, i%cols
+		h.top = row*area/rows + titleRows
+		h.height = (row+1)*area/rows - h.top
+		h.left = col * (width + 1) / cols
+		h.width = (col+1)*(width+1)/cols - 1 - h.left
+		if h.height < 1 {
+			h.height = 1
+		}
+		if h.width < 1 {
+			h.width = 1
+		}
+	}
+	b.resize()
+}
+
+// resize sizes every pty like the view shows it,the whole terminal in the
+// focus view so full screen programs look the same on every host.
+func (b *Broadcast) resize() {
+	for _, h := range b.hosts {
+		width, height := h.width, h.height
+		if b.focused {
+			width, height = b.width, b.height
+		}
+		h.screen.width = width
+		if h.exited || (width == h.ptyWidth && height == h.ptyHeight) {
+			continue
+		}
+		h.ptyWidth, h.ptyHeight = width, height
+		if h.Resize != nil {
+			h.Resize(width, height)
+		}
+	}
+}
+
+// redraw clears the terminal and draws the current view.
+func (b *Broadcast) redraw() {
+	if b.focused {
+		b.showFocus()
+		return
+	}
+	b.out.Write([]byte("\x1b[H\x1b[2J"))
+	b.render()
+}
+
+func (b *Broadcast) status() string {
+	on := 0
+	for _, h := range b.hosts {
+		if h.on {
+			on++
+		}
+	}
+	return fmt.Sprintf("broadcast to %d/%d", on, len(b.hosts))
+}
+
+// showFocus starts the focus view with what the selected shell showed last,
+// its further output is passed through.
+func (b *Broadcast) showFocus() {
+	h := b.hosts[b.selected]
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[?25h\x1b[H\x1b[2J")
+	fmt.Fprintf(&buf, "\x1b[7m %s \x1b[0m\x1b[2m %s,%s,ctrl-] f back to the panes\x1b[0m\r\n",
+		h.Name, hostState(h), b.status())
+	lines := h.screen.last(b.height - 1)
+	buf.WriteString(strings.Join(lines, "\r\n"))
+	if h.screen.col > 0 {
+		fmt.Fprintf(&buf, "\r\x1b[%dC", h.screen.col)
+	}
+	b.out.Write(buf.Bytes())
+}
+
+func hostState(h *host) string {
+	switch {
+	case h.exited:
+		return "exited"
+	case h.on:
+		return "on"
+	}
+	return "off"
+}
+
+// render draws the panes and the status line. The terminal is in raw mode,
+// every line is placed with the cursor instead of \r\n.
+func (b *Broadcast) render() {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[?25l")
+	for i, h := range b.hosts {
+		title := pad(fmt.Sprintf(" %s [%s]", h.Name, hostState(h)), h.width)
+		switch {
+		case i == b.selected:
+			title = "\x1b[7m" + title + "\x1b[0m"
+		case !h.on:
+			title = "\x1b[2m" + title + "\x1b[0m"
+		default:
+			title = "\x1b[1m" + title + "\x1b[0m"
+		}
+		fmt.Fprintf(&buf, "\x1b[%d;%dH%s", h.top-titleRows+1, h.left+1, title)
+		lines := h.screen.last(h.height)
+		for row := 0; row < h.height; row++ {
+			line := ""
+			if row < len(lines) {
+				line = lines[row]
+			}
+			fmt.Fprintf(&buf, "\x1b[%d;%dH%s", h.top+row+1, h.left+1, pad(line, h.width))
+		}
+		// the column right of the pane separates it from the next one
+		if right := h.left + h.width; right < b.width {
+			for row := h.top - titleRows; row < h.top+h.height; row++ {
+				fmt.Fprintf(&buf, "\x1b[%d;%dH\x1b[2m│\x1b[0m", row+1, right+1)
+			}
+		}
+	}
+	help := " ctrl-] for commands"
+	if b.prefix {
+		help = " f focus,n/p select,t toggle,a all,o only,q quit,ctrl-] send it"
+	}
+	status := pad(" "+b.status()+" │"+help, b.width)
+	if b.prefix {
+		status = "\x1b[7m" + status + "\x1b[0m"
+	}
+	fmt.Fprintf(&buf, "\x1b[%d;1H%s", b.height, status)
+	// the cursor stays where the selected shell left it
+	h := b.hosts[b.selected]
+	lines := h.screen.last(h.height)
+	col := h.screen.col
+	if col >= h.width {
+		col = h.width - 1
+	}
+	fmt.Fprintf(&buf, "\x1b[%d;%dH\x1b[?25h", h.top+len(lines), h.left+col+1)
+	b.out.Write(buf.Bytes())
+}
+
+// pad cuts or fills s with spaces to width columns.
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	} else if n > width {
+		return string([]rune(s)[:width])
+	}
+	return s
+}
+
+// Start broadcasts to targets on the terminal behind stdin and stdout,drawn
+// on the alternate screen so the shell output is left as it was.
+func Start(targets []*Target) error {
+	fd := int(os.Stdin.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer terminal.Restore(fd, state)
+	os.Stdout.WriteString("\x1b[?1049h")
+	defer os.Stdout.WriteString("\x1b[?25h\x1b[?1049l")
+	size := func() (int, int) {
+		width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+		if err != nil || width <= 0 || height <= 0 {
+			return 80, 24
+		}
+		return width, height
+	}
+	return New(targets).Run(os.Stdin, os.Stdout, size)
+}
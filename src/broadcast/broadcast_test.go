@@ -0,0 +1,86 @@
+package broadcast
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestScreen(t *testing.T) {
+	for _, c := range []struct {
+		writes []string
+		width  int
+		expect []string
+	}{
+		{[]string{"ab\bc\r\n"}, 0, []string{"ac", ""}},
+		{[]string{"hello\x1b[3D\x1b[K"}, 0, []string{"he"}},
+		{[]string{"\x1b[32mok\x1b[0m \x1b]0;title\x07x"}, 0, []string{"ok x"}},
+		{[]string{"abcdef"}, 4, []string{"abcd", "ef"}},
+		{[]string{"caf\xc3", "\xa9\t|"}, 0, []string{"café    |"}},
+		{[]string{"old\r\n\x1b[H\x1b[2Jnew"}, 0, []string{"new"}},
+	} {
+		s := newScreen(c.width)
+		for _, w := range c.writes {
+			s.Write([]byte(w))
+		}
+		if got := s.last(10); strings.Join(got, "|") != strings.Join(c.expect, "|") {
+			t.Errorf("%q got %q, expect %q", c.writes, got, c.expect)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	b := New([]*Target{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}})
+	b.layout(80, 25)
+	for i, expect := range [][4]int{{1, 0, 39, 11}, {1, 40, 40, 11}, {13, 0, 39, 11}, {13, 40, 40, 11}} {
+		h := b.hosts[i]
+		if got := [4]int{h.top, h.left, h.width, h.height}; got != expect {
+			t.Errorf("pane %d got top,left,width,height %v, expect %v", i, got, expect)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	inputs := make([]*bytes.Buffer, 2)
+	writers := make([]*io.PipeWriter, 2)
+	targets := make([]*Target, 2)
+	for i := range targets {
+		r, w := io.Pipe()
+		inputs[i], writers[i] = &bytes.Buffer{}, w
+		targets[i] = &Target{Name: string('a' + rune(i)), Input: inputs[i], Output: r}
+	}
+	defer func() {
+		for _, w := range writers {
+			w.Close()
+		}
+	}()
+	size := func() (int, int) { return 80, 24 }
+	// ls to both,then the first is toggled off,x only goes to the second
+	keys := strings.NewReader("ls\r\x1dtx\x1d\x1d\x1dq")
+	if err := New(targets).Run(keys, ioutil.Discard, size); err != nil {
+		t.Fatal(err)
+	}
+	for i, expect := range []string{"ls\r", "ls\rx\x1d"} {
+		if got := inputs[i].String(); got != expect {
+			t.Errorf("shell %d got %q, expect %q", i, got, expect)
+		}
+	}
+}
+
+func TestRunExited(t *testing.T) {
+	r, w := io.Pipe()
+	keys, typing := io.Pipe()
+	defer typing.Close()
+	done := make(chan error)
+	go func() {
+		done <- New([]*Target{{Name: "a", Input: ioutil.Discard, Output: r}}).Run(keys, ioutil.Discard,
+			func() (int, int) { return 80, 24 })
+	}()
+	w.Write([]byte("bye\r\n"))
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
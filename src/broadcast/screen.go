@@ -0,0 +1,194 @@
+package broadcast
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLines bounds the lines a screen keeps of a shell.
+const maxLines = 500
+
+const (
+	stateText = iota
+	stateEscape
+	stateCSI
+	stateString //OSC,DCS and the like,up to BEL or ESC \
+)
+
+// screen keeps what a shell printed as plain lines of text,enough to follow
+// a prompt and command output in a pane. It understands line breaks and the
+// few escape sequences line editing uses,others are dropped,so full screen
+// programs are only readable in the focus view.
+type screen struct {
+	lines   [][]rune
+	col     int //cursor column in the last line
+	width   int
+	state   int
+	params  []byte //of the escape sequence being read
+	pending []byte //an incomplete utf-8 sequence
+}
+
+func newScreen(width int) *screen {
+	return &screen{lines: [][]rune{nil}, width: width}
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(s.pending) > 0 {
+		p = append(s.pending, p...)
+		s.pending = nil
+	}
+	for len(p) > 0 {
+		if s.state == stateText && p[0] >= utf8.RuneSelf {
+			if !utf8.FullRune(p) {
+				s.pending = append([]byte(nil), p...)
+				break
+			}
+			r, size := utf8.DecodeRune(p)
+			p = p[size:]
+			s.put(r)
+			continue
+		}
+		s.byte(p[0])
+		p = p[1:]
+	}
+	return n, nil
+}
+
+func (s *screen) byte(b byte) {
+	switch s.state {
+	case stateEscape:
+		switch b {
+		case '[':
+			s.state, s.params = stateCSI, s.params[:0]
+		case ']', 'P', 'X', '^', '_':
+			s.state = stateString
+		default:
+			s.state = stateText
+		}
+		return
+	case stateCSI:
+		if b >= 0x40 && b <= 0x7e {
+			s.state = stateText
+			s.csi(b)
+		} else {
+			s.params = append(s.params, b)
+		}
+		return
+	case stateString:
+		switch b {
+		case 7:
+			s.state = stateText
+		case 27:
+			s.state = stateEscape //ESC \ ends the string,the \ is then dropped
+		}
+		return
+	}
+	switch b {
+	case 27:
+		s.state = stateEscape
+	case '\n':
+		s.newLine()
+	case '\r':
+		s.col = 0
+	case '\b':
+		if s.col > 0 {
+			s.col--
+		}
+	case '\t':
+		s.put(' ')
+		for s.col%8 != 0 {
+			s.put(' ')
+		}
+	default:
+		if b >= ' ' && b != 127 {
+			s.put(rune(b))
+		}
+	}
+}
+
+// csi applies the sequences that change the current line,the others move
+// the cursor over the whole screen and are ignored.
+func (s *screen) csi(final byte) {
+	n := 1
+	if v, err := strconv.Atoi(strings.TrimPrefix(string(s.params), "?")); err == nil && v > 0 {
+		n = v
+	}
+	line := s.lines[len(s.lines)-1]
+	switch final {
+	case 'C':
+		s.col += n
+	case 'D':
+		if s.col -= n; s.col < 0 {
+			s.col = 0
+		}
+	case 'G':
+		s.col = n - 1
+	case 'K':
+		// 0 or nothing erases to the end of the line,2 the whole line
+		switch string(s.params) {
+		case "", "0":
+			if s.col < len(line) {
+				s.lines[len(s.lines)-1] = line[:s.col]
+			}
+		case "2":
+			s.lines[len(s.lines)-1] = nil
+		}
+	case 'P':
+		if s.col < len(line) {
+			end := s.col + n
+			if end > len(line) {
+				end = len(line)
+			}
+			s.lines[len(s.lines)-1] = append(line[:s.col], line[end:]...)
+		}
+	case '@':
+		if s.col < len(line) {
+			blanks := []rune(strings.Repeat(" ", n))
+			s.lines[len(s.lines)-1] = append(line[:s.col], append(blanks, line[s.col:]...)...)
+		}
+	case 'J':
+		if string(s.params) == "2" || string(s.params) == "3" {
+			s.lines, s.col = [][]rune{nil}, 0
+		}
+	}
+}
+
+func (s *screen) put(r rune) {
+	if s.width > 0 && s.col >= s.width {
+		s.newLine()
+	}
+	line := s.lines[len(s.lines)-1]
+	for len(line) < s.col {
+		line = append(line, ' ')
+	}
+	if s.col < len(line) {
+		line[s.col] = r
+	} else {
+		line = append(line, r)
+	}
+	s.lines[len(s.lines)-1] = line
+	s.col++
+}
+
+func (s *screen) newLine() {
+	s.lines = append(s.lines, nil)
+	s.col = 0
+	if len(s.lines) > maxLines {
+		s.lines = s.lines[len(s.lines)-maxLines:]
+	}
+}
+
+// last returns up to n of the last lines,the cursor is on the last of them.
+func (s *screen) last(n int) []string {
+	start := len(s.lines) - n
+	if start < 0 {
+		start = 0
+	}
+	lines := make([]string, 0, len(s.lines)-start)
+	for _, line := range s.lines[start:] {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
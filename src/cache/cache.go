@@ -2,15 +2,18 @@ package cache
 
 import (
 	"encode"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"meta"
 	"os"
+	"path/filepath"
 	"pb"
 	"sort"
 	"strings"
+	"time"
 	"utils"
 )
 
@@ -18,6 +21,7 @@ type Cache struct {
 	GroupCache    map[string]int32      `json:"groups"`
 	NodeCache     map[string]*meta.Node `json:"nodes"`
 	GroupRefNodes map[string][]string   `json:"group_ref"`
+	ExpireAt      int64                 `json:"expire_at"` //unix seconds
 }
 
 func InitCache(c *Cache, res *pb.QueryResponse) error {
@@ -33,12 +37,16 @@ func InitCache(c *Cache, res *pb.QueryResponse) error {
 	}
 	for _, n := range res.NodeMetas {
 		node := &meta.Node{
-			Ip:        n.Host,
-			Port:      int(n.Port),
-			UserName:  n.Username,
-			Password:  n.Password,
-			Tag:       n.Tag,
-			GroupName: n.Group,
+			Ip:          n.Host,
+			Port:        int(n.Port),
+			UserName:    n.Username,
+			Password:    n.Password,
+			Key:         n.Key,
+			Passphrase:  n.Passphrase,
+			AgentSocket: n.AgentSocket,
+			HostKey:     n.HostKey,
+			Tag:         n.Tag,
+			GroupName:   n.Group,
 		}
 		if c.GroupRefNodes[n.Group] == nil {
 			c.GroupRefNodes[n.Group] = make([]string, 0)
@@ -59,41 +67,44 @@ func InitCache(c *Cache, res *pb.QueryResponse) error {
 	return nil
 }
 
-func (c *Cache) Encode() ([]byte, error) {
+func (c *Cache) Encode(key []byte) ([]byte, error) {
 	b, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	rb, err := encode.Encoding(b)
+	cipher, err := encode.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
-	return rb, nil
+	return cipher.Encrypt(b)
 }
-func (c *Cache) Decode(b []byte) error {
-	rb, err := encode.Decoding(b)
+func (c *Cache) Decode(key, b []byte) error {
+	cipher, err := encode.NewCipher(key)
 	if err != nil {
 		return err
 	}
-	if err = json.Unmarshal(rb, c); err != nil {
-		return nil
+	rb, err := cipher.Decrypt(b)
+	if err != nil {
+		return err
 	}
-	return nil
+	return json.Unmarshal(rb, c)
+}
+
+// Expired reports whether the server must be asked again before using c.
+func (c *Cache) Expired() bool {
+	return time.Now().Unix() >= c.ExpireAt
 }
-func (c *Cache) ReplaceCacheFile(path string) error {
+
+// ReplaceCacheFile seals c with key and atomically replaces ~/path,readable
+// only by the current user.
+func (c *Cache) ReplaceCacheFile(path string, key []byte) error {
 	rootPath, _ := utils.Expand(fmt.Sprintf("~/%s", path))
-	if _, err := os.Stat(rootPath); os.IsNotExist(err) {
-		if _, err = os.Create(rootPath); err != nil {
-			fmt.Println("replaceCacheFile:", err)
-			return err
-		}
-	}
-	wb, err := c.Encode()
+	wb, err := c.Encode(key)
 	if err != nil {
 		return err
 	}
 	oldPath, _ := utils.Expand(fmt.Sprintf("~/%s", fmt.Sprintf(".%s.temp", path)))
-	if err = ioutil.WriteFile(oldPath, wb, os.ModePerm); err != nil {
+	if err = ioutil.WriteFile(oldPath, wb, 0600); err != nil {
 		return err
 	}
 	if err = os.Rename(oldPath, rootPath); err != nil {
@@ -102,16 +113,31 @@ func (c *Cache) ReplaceCacheFile(path string) error {
 
 	return nil
 }
-func (c *Cache) Flush(path string) error {
-	var rb []byte
-	var err error
-	if rb, err = json.Marshal(c); err != nil {
+func (c *Cache) Flush(path string, key []byte) error {
+	rb, err := c.Encode(key)
+	if err != nil {
 		return err
 	}
-	if rb, err = c.Encode(); err != nil {
+	return ioutil.WriteFile(path, rb, 0600)
+}
+
+// SaveKey keeps the key handed out by the server so the cache stays usable
+// while the server is unreachable,until the cache expires. path should not be
+// next to the cache file,such as in the runtime dir of the user.
+// SaveKey writes key to path readable only by the current user,creating its
+// dir if needed.
+func SaveKey(path string, key []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return err
 	}
-	return ioutil.WriteFile(path, rb, os.ModePerm)
+	return ioutil.WriteFile(path, []byte(hex.EncodeToString(key)), 0600)
+}
+func LoadKey(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(b)))
 }
 func (c *Cache) OrderNode() []*meta.Node {
 	nodes := make([]*meta.Node, 0)
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"encode"
+	"meta"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	master, _ := encode.NewCipher(encode.DeriveKey("secret"))
+	key := master.SubKey("cache:root")
+	c := &Cache{
+		NodeCache: map[string]*meta.Node{"10.0.0.1": {Ip: "10.0.0.1", Password: "pwd"}},
+		ExpireAt:  time.Now().Add(time.Hour).Unix(),
+	}
+	b, err := c.Encode(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := &Cache{}
+	if err = rc.Decode(key, b); err != nil {
+		t.Fatal(err)
+	}
+	if rc.Expired() || rc.NodeCache["10.0.0.1"].Password != "pwd" {
+		t.Fatal("unexpected cache ", rc)
+	}
+	if err = rc.Decode(master.SubKey("cache:guest"), b); err == nil {
+		t.Fatal("cache of root decoded with key of guest")
+	}
+	rc.ExpireAt = time.Now().Add(-time.Second).Unix()
+	if !rc.Expired() {
+		t.Fatal("cache should be expired")
+	}
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HostCompletions returns the cached ips starting with prefix,described by
+// their group and tag. Completions are "value\tdescription" as shells show them.
+func (c *Cache) HostCompletions(prefix string) []string {
+	res := make([]string, 0)
+	for _, node := range c.OrderNode() {
+		if !strings.HasPrefix(node.Ip, prefix) {
+			continue
+		}
+		desc := node.GroupName
+		if len(node.Tag) > 0 {
+			desc = fmt.Sprintf("%s,tag %s", desc, node.Tag)
+		}
+		res = append(res, node.Ip+"\t"+desc)
+	}
+	return res
+}
+
+// GroupCompletions returns the cached groups starting with prefix,described
+// by their node count.
+func (c *Cache) GroupCompletions(prefix string) []string {
+	counts := make(map[string]int)
+	for _, node := range c.NodeCache {
+		counts[strings.ToLower(node.GroupName)]++
+	}
+	return countCompletions(counts, strings.ToLower(prefix))
+}
+
+// TagCompletions returns the cached tags starting with prefix,described by
+// their node count.
+func (c *Cache) TagCompletions(prefix string) []string {
+	counts := make(map[string]int)
+	for _, node := range c.NodeCache {
+		if len(node.Tag) > 0 {
+			counts[strings.ToLower(node.Tag)]++
+		}
+	}
+	return countCompletions(counts, strings.ToLower(prefix))
+}
+
+func countCompletions(counts map[string]int, prefix string) []string {
+	res := make([]string, 0)
+	for name, count := range counts {
+		if strings.HasPrefix(name, prefix) {
+			res = append(res, fmt.Sprintf("%s\t%d nodes", name, count))
+		}
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"meta"
+	"strings"
+	"utils"
+)
+
+// Selector narrows the cached nodes down to a target set. Values inside one
+// field are or-ed, the non-empty fields are and-ed, and Excludes is applied last.
+type Selector struct {
+	Groups   []string
+	Tags     []string
+	Hosts    []string // ip, cidr like 10.0.0.0/24 or glob like 10.0.0.*
+	Excludes []string // same syntax as Hosts
+}
+
+func SplitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (s *Selector) Empty() bool {
+	return s == nil || (len(s.Groups) == 0 && len(s.Tags) == 0 && len(s.Hosts) == 0 && len(s.Excludes) == 0)
+}
+
+func matchAny(patterns []string, ip string) bool {
+	for _, pattern := range patterns {
+		if utils.MatchHost(pattern, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the nodes matched by s ordered like OrderNode.
+func (c *Cache) Select(s *Selector) []*meta.Node {
+	nodes := c.OrderNode()
+	if s.Empty() {
+		return nodes
+	}
+	var groupHosts map[string]uint8
+	if len(s.Groups) > 0 {
+		groupHosts = make(map[string]uint8)
+		for _, groupName := range s.Groups {
+			for _, ip := range c.GroupRefNodes[strings.ToLower(groupName)] {
+				groupHosts[ip] = 1
+			}
+		}
+	}
+	selected := make([]*meta.Node, 0)
+	for _, node := range nodes {
+		if groupHosts != nil {
+			if _, ok := groupHosts[node.Ip]; !ok {
+				continue
+			}
+		}
+		if len(s.Tags) > 0 {
+			matched := false
+			for _, tag := range s.Tags {
+				if strings.EqualFold(tag, node.Tag) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		if len(s.Hosts) > 0 && !matchAny(s.Hosts, node.Ip) {
+			continue
+		}
+		if matchAny(s.Excludes, node.Ip) {
+			continue
+		}
+		selected = append(selected, node)
+	}
+	return selected
+}
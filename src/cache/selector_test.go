@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"meta"
+	"testing"
+)
+
+func testCache() *Cache {
+	c := &Cache{
+		NodeCache:     make(map[string]*meta.Node),
+		GroupRefNodes: make(map[string][]string),
+		GroupCache:    make(map[string]int32),
+	}
+	nodes := []*meta.Node{
+		{Ip: "10.0.0.1", Tag: "d1", GroupName: "web"},
+		{Ip: "10.0.0.2", Tag: "d2", GroupName: "web"},
+		{Ip: "10.0.1.1", Tag: "d1", GroupName: "db"},
+		{Ip: "192.168.1.5", Tag: "d1", GroupName: "image"},
+	}
+	for _, node := range nodes {
+		c.NodeCache[node.Ip] = node
+		c.GroupRefNodes[node.GroupName] = append(c.GroupRefNodes[node.GroupName], node.Ip)
+		c.GroupCache[node.GroupName]++
+	}
+	return c
+}
+
+func ips(nodes []*meta.Node) []string {
+	res := make([]string, 0)
+	for _, node := range nodes {
+		res = append(res, node.Ip)
+	}
+	return res
+}
+
+func TestSelect(t *testing.T) {
+	c := testCache()
+	cases := []struct {
+		sel    *Selector
+		expect []string
+	}{
+		{nil, []string{"10.0.1.1", "192.168.1.5", "10.0.0.1", "10.0.0.2"}},
+		{&Selector{Groups: []string{"web"}}, []string{"10.0.0.1", "10.0.0.2"}},
+		{&Selector{Groups: []string{"WEB", "db"}, Tags: []string{"d1"}}, []string{"10.0.1.1", "10.0.0.1"}},
+		{&Selector{Hosts: []string{"10.0.0.0/16"}, Excludes: []string{"10.0.0.2"}}, []string{"10.0.1.1", "10.0.0.1"}},
+		{&Selector{Hosts: []string{"192.168.*"}}, []string{"192.168.1.5"}},
+		{&Selector{Tags: []string{"d1"}, Excludes: []string{"10.0.*"}}, []string{"192.168.1.5"}},
+	}
+	for i, cs := range cases {
+		got := ips(c.Select(cs.sel))
+		if len(got) != len(cs.expect) {
+			t.Fatalf("case %d: got %v, expect %v", i, got, cs.expect)
+		}
+		for j := range got {
+			if got[j] != cs.expect[j] {
+				t.Fatalf("case %d: got %v, expect %v", i, got, cs.expect)
+			}
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := SplitList(" web, db,,image ")
+	if len(got) != 3 || got[0] != "web" || got[2] != "image" {
+		t.Fatal("unexpected split result:", got)
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	c := testCache()
+	hosts := c.HostCompletions("10.0.0")
+	if len(hosts) != 2 || hosts[0] != "10.0.0.1\tweb,tag d1" {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+	groups := c.GroupCompletions("")
+	if len(groups) != 3 || groups[2] != "web\t2 nodes" {
+		t.Errorf("unexpected groups %v", groups)
+	}
+	tags := c.TagCompletions("D")
+	if len(tags) != 2 || tags[0] != "d1\t3 nodes" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
@@ -1,330 +1,389 @@
 package main
 
 import (
+	"bytes"
 	"cache"
 	"conn"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"meta"
 	"os"
 	"pb"
+	"record"
 	"sort"
 	"ssh"
 	"strings"
-	"text/tabwriter"
+	"time"
 	"utils"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/net/context"
 )
 
 const (
 	defaultClusterTemplateFile     = "template_cluster.json"
 	defaultClusterServerConfigFile = ".vsh_config.json"
 	defaultCacheClusterFile        = ".vsh_cache.json"
+	defaultCacheKeyFile            = "vsh_cache.key" //in $XDG_RUNTIME_DIR or the user config dir
+	defaultRecordDir               = "~/.vsh_recordings"
 )
 
 type Config struct {
-	Addr string `json:"addr"`
-	Port int    `json:"port"`
+	Addr       string `json:"addr"`
+	Port       int    `json:"port"`
+	CA         string `json:"ca,omitempty"`          //ca to verify server,enable tls
+	Cert       string `json:"cert,omitempty"`        //client cert for mutual tls
+	Key        string `json:"key,omitempty"`         //client key for mutual tls
+	ServerName string `json:"server_name,omitempty"` //override name checked in server cert
+	RecordDir  string `json:"record_dir,omitempty"`  //where sessions are recorded,"off" disables
 }
 
-var formatWriter *tabwriter.Writer
-
-func init() {
-	if formatWriter == nil {
-		formatWriter = new(tabwriter.Writer)
-		formatWriter.Init(os.Stdout, 10, 4, 6, ' ', 0)
+// removeCache drops the cache and its key,such as after the server denied us.
+func removeCache() {
+	cacheFile, keyFile := cacheFiles()
+	if file, err := utils.Expand(fmt.Sprintf("~/%s", cacheFile)); err == nil {
+		os.Remove(file)
+	}
+	if len(keyFile) > 0 {
+		os.Remove(keyFile)
+	}
+}
+func readCache(key []byte) (*cache.Cache, error) {
+	cacheFile, _ := cacheFiles()
+	cacheFile, _ = utils.Expand(fmt.Sprintf("~/%s", cacheFile))
+	b, err := ioutil.ReadFile(cacheFile)
+	if err != nil {
+		return nil, err
 	}
+	c := &cache.Cache{}
+	if err = c.Decode(key, b); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
+// offlineCache serves the cache while the server is unreachable,until it expires.
+func offlineCache(cause error) (*cache.Cache, error) {
+	_, keyFile := cacheFiles()
+	if len(keyFile) == 0 {
+		return nil, cause
+	}
+	key, err := cache.LoadKey(keyFile)
+	if err != nil {
+		return nil, cause
+	}
+	c, err := readCache(key)
+	if err != nil {
+		return nil, cause
+	}
+	if c.Expired() {
+		return nil, errors.New("cache expired and server is unreachable: " + cause.Error())
+	}
+	return c, nil
+}
 func fetchCache(groupNames []string) (*cache.Cache, error) {
-	var force bool
-	cacheFile, _ := utils.Expand(fmt.Sprintf("~/%s", defaultCacheClusterFile))
-	cli, err := initConn(defaultClusterServerConfigFile)
+	cli, err := initConn(configFile)
 	if err != nil {
 		return nil, err
 	}
 	defer cli.Close()
 	resp, err := cli.NewCacheSession()
+	if conn.IsUnavailable(err) {
+		return offlineCache(err)
+	}
 	if err != nil {
+		removeCache()
 		return nil, err
 	}
-	if resp.Response == 1 {
-		force = true
-	}
-	if _, err := os.Stat(cacheFile); os.IsNotExist(err) {
-		force = true
-	}
 	var c *cache.Cache
-	if force {
-		var res *pb.QueryResponse
-		c = &cache.Cache{
-			NodeCache:     make(map[string]*meta.Node),
-			GroupRefNodes: make(map[string][]string),
-			GroupCache:    make(map[string]int32),
-		}
-		if res, err = cli.NewViewSession(groupNames); err != nil {
-			return nil, err
-		}
-		if err = cache.InitCache(c, res); err != nil {
-			return nil, err
+	if resp.Response != 1 {
+		if c, err = readCache(resp.Key); err == nil && !c.Expired() {
+			return c, nil
 		}
+	}
+	var res *pb.QueryResponse
+	c = &cache.Cache{
+		NodeCache:     make(map[string]*meta.Node),
+		GroupRefNodes: make(map[string][]string),
+		GroupCache:    make(map[string]int32),
+		ExpireAt:      resp.ExpireAt,
+	}
+	if res, err = cli.NewViewSession(groupNames); err != nil {
+		return nil, err
+	}
+	if err = cache.InitCache(c, res); err != nil {
+		return nil, err
+	}
 
-		if err = c.ReplaceCacheFile(defaultCacheClusterFile); err != nil {
-			return nil, err
-		}
+	// a partial view must not replace the full node list kept in the cache file
+	if len(groupNames) > 0 {
 		return c, nil
 	}
-	b, err := ioutil.ReadFile(cacheFile)
-	if err != nil {
+	cacheFile, keyFile := cacheFiles()
+	if err = c.ReplaceCacheFile(cacheFile, resp.Key); err != nil {
 		return nil, err
 	}
-	c = &cache.Cache{}
-	if err = c.Decode(b); err != nil {
+	if len(keyFile) == 0 {
+		fmt.Fprintln(os.Stderr, "no runtime or config dir to keep the cache key,the cache is not used offline")
+		return c, nil
+	}
+	if err = cache.SaveKey(keyFile, resp.Key); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
+
+// loadConfig returns the server config of the context in use.
+func loadConfig(path string) (*Config, error) {
+	_, conf, err := loadProfile(path)
+	return conf, err
+}
 func initConn(path string) (*conn.Conn, error) {
-	rootPath, _ := utils.Expand(fmt.Sprintf("~/%s", path))
-	var conf Config
-	b, err := ioutil.ReadFile(rootPath)
+	conf, err := loadConfig(path)
 	if err != nil {
 		return nil, err
 	}
-	if err = json.Unmarshal(b, &conf); err != nil {
+	tlsOption := &conn.TLSOption{
+		ServerName: conf.ServerName,
+	}
+	if tlsOption.CA, err = utils.Expand(conf.CA); err != nil {
 		return nil, err
 	}
-	return conn.NewConn(conf.Addr, conf.Port)
+	if tlsOption.Cert, err = utils.Expand(conf.Cert); err != nil {
+		return nil, err
+	}
+	if tlsOption.Key, err = utils.Expand(conf.Key); err != nil {
+		return nil, err
+	}
+	return conn.NewConn(conf.Addr, conf.Port, tlsOption)
 
 }
-func usage() {
-	fmt.Println("Usage:")
-	fmt.Println("vsh [group|user|ip|template|dump|load|delete|{option_ip} run]")
-	fmt.Println("Available Commands:")
-	fmt.Println("user      list current users")
-	fmt.Println("group     list node group info")
-	fmt.Println("delete    delete nodes of group")
-	fmt.Println("dump      dump cluster info")
-	fmt.Println("load      load nodes")
-	fmt.Println("run       execute shell command")
-	fmt.Println("template  create  cluster.json")
-	fmt.Println("help      help for user")
+
+// connect dials the server of the current profile and checks the caller. An
+// unreachable server still leaves the unexpired cache usable,access is nil then.
+func connect() (*conn.Conn, *pb.BasicResponse, error) {
+	cli, err := initConn(configFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("init connection: %v", err)
+	}
+	access, err := cli.NewBasicSession()
+	if err != nil && !conn.IsUnavailable(err) {
+		cli.Close()
+		removeCache()
+		return nil, nil, err
+	}
+	return cli, access, nil
+}
+
+// report records an action the client ran on nodes itself in the server audit
+// log. The action already ran,a failed report only warns.
+func report(cli *conn.Conn, action string, targets []string, result, detail string) {
+	if _, err := cli.NewReportSession(action, targets, result, detail); err != nil {
+		fmt.Fprintf(os.Stderr, "report %s to the server: %v\n", action, err)
+	}
 }
-func hanleOneCmd(names []string) {
-	var cmdName string
-	var ip string
-	if names == nil {
-		cmdName = "node"
-	} else {
-		cmdName = strings.ToLower(names[0])
-		if utils.ValidIpAddr(names[0]) {
-			cmdName = "host"
-			ip = names[0]
-		} else {
-			cmdName = strings.ToLower(names[0])
-		}
+
+// newRecorder starts recording a session to node in the configured record dir.
+// Recording is best effort,a failure is reported and the session goes on.
+func newRecorder(node string) *record.Recorder {
+	dir := defaultRecordDir
+	if conf, err := loadConfig(configFile); err == nil && len(conf.RecordDir) > 0 {
+		dir = conf.RecordDir
 	}
-	defer formatWriter.Flush()
-	cli, err := initConn(defaultClusterServerConfigFile)
+	if dir == "off" {
+		return nil
+	}
+	dir, err := utils.Expand(dir)
 	if err != nil {
-		fmt.Println("init conn:", err)
-		return
+		fmt.Println("record session:", err)
+		return nil
 	}
-	_, err = cli.NewBasicSession()
+	username, _ := utils.GetUserName()
+	width, height, err := terminal.GetSize(int(os.Stdin.Fd()))
 	if err != nil {
-		fmt.Println(err)
-		os.Remove(defaultCacheClusterFile)
-		return
+		width, height = 80, 24
 	}
-	c, err := fetchCache(nil)
+	rec, err := record.NewRecorder(dir, username, node, width, height)
 	if err != nil {
-		fmt.Println("fetchCache :", err.Error())
-		return
-	}
-	switch cmdName {
-	case "node":
-		fmt.Fprintln(formatWriter, "host\ttag\tgroup")
-		if len(c.GroupRefNodes) == 0 {
-			fmt.Println("empty nodes")
-			return
-		}
-		nodes := c.OrderNode()
-		for _, node := range nodes {
-			fmt.Fprintf(formatWriter, "%s\t%s\t%s\n", node.Ip, node.Tag, node.GroupName)
-		}
-		return
-	case "group":
-		fmt.Fprintln(formatWriter, "nodes\tgroup")
-		if len(c.GroupCache) == 0 {
-			fmt.Println("enmpty group")
-			return
-		}
+		fmt.Println("record session:", err)
+		return nil
+	}
+	return rec
+}
 
-		groupNames := c.OrderGroup()
-		for _, groupName := range groupNames {
-			fmt.Fprintf(formatWriter, "%d\t%s\n", c.GroupCache[groupName], groupName)
-		}
-		break
-	case "user":
-		resp, err := cli.NewUserSession()
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		fmt.Fprintln(formatWriter, "user\ttype")
-		for userName, userType := range resp.Response {
-			fmt.Fprintf(formatWriter, "%s\t%v\n", userName, userType)
-		}
-		break
-	case "host":
-		cache, err := fetchCache(nil)
-		if err != nil {
-			fmt.Println("go host:", err)
-			return
-		}
-		if _, ok := cache.NodeCache[ip]; !ok {
-			fmt.Println("unknown node:", ip)
-			return
-		}
-		node := cache.NodeCache[ip]
-		if err = ssh.NewSSHConnection(node); err != nil {
-			fmt.Printf("connect %s:%d:%v\n", node.Ip, node.Port, err)
-			return
-		}
-		break
-	case "template":
-		if _, err := os.Stat(defaultClusterTemplateFile); os.IsNotExist(err) {
-			utils.CreateTemplate(defaultClusterTemplateFile)
-			fmt.Println("create template success")
-			return
-		}
-		fmt.Println("template exists in ", defaultClusterTemplateFile)
-		break
-	case "dump":
-		_, err = cli.NewDumpSession()
-		if err != nil {
-			fmt.Println(err)
-			return
+// parseTime accepts a duration back from now,such as 24h,or a local date time.
+func parseTime(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
 		}
-		fmt.Println("dump success on remote!")
-		break
-	default:
-		usage()
-		break
 	}
+	return time.Time{}, fmt.Errorf("invalid time %s", s)
+}
 
+func printGrants(grants []*pb.GrantEntry) error {
+	return printRecords([]string{"id", "user", "groups", "tags", "addresses", "roles", "status", "reason"}, grantRecords(grants))
 }
 
-func hanleMultiCmd(args []string) {
-	if args == nil || len(args) <= 1 {
-		usage()
-		return
+// permitted reports whether the roles of the caller grant perm. Nothing is
+// known while the server is unreachable or when it predates roles,then the
+// cache decides.
+func permitted(access *pb.BasicResponse, perm string) bool {
+	if access == nil || len(access.Permissions) == 0 {
+		return true
 	}
-	cmdName := strings.ToLower(args[0])
-	cli, err := initConn(defaultClusterServerConfigFile)
-	if err != nil {
-		fmt.Println("init connection:", err)
-		return
+	for _, p := range access.Permissions {
+		if p == perm {
+			return true
+		}
 	}
-	_, err = cli.NewBasicSession()
-	if err != nil {
-		fmt.Println(err)
-		os.Remove(defaultCacheClusterFile)
-		return
+	return false
+}
+func printRunResult(res *ssh.Result) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "********************%s***************************\n", res.Node.Ip)
+	fmt.Fprintf(&buf, "%s $ %s\n", res.Node.Ip, res.Cmd)
+	buf.Write(res.Stdout)
+	buf.Write(res.Stderr)
+	if res.Err != nil {
+		fmt.Fprintln(&buf, res.Err)
+	} else {
+		fmt.Fprintln(&buf)
 	}
-	if strings.Compare(cmdName, "load") == 0 || strings.Compare(cmdName, "delete") == 0 {
+	os.Stdout.Write(buf.Bytes())
+}
 
-		cacheFile, err := utils.Expand(fmt.Sprintf("~/%s", defaultCacheClusterFile))
-		if err != nil {
-			fmt.Println("expand home dir:", err)
+// summarize prints how many hosts succeeded,failed or were unreachable to
+// stderr,naming the others. The error carries the exit code of vsh.
+func summarize(results []*ssh.Result) error {
+	byStatus := make(map[string][]string, 3)
+	for _, res := range results {
+		status := res.Status()
+		if status == ssh.StatusSucceeded {
+			byStatus[status] = append(byStatus[status], res.Node.Ip)
+			continue
 		}
-		if _, err := os.Stat(cacheFile); err == nil {
-			os.Remove(cacheFile)
+		why := string(res.Kind)
+		switch res.Kind {
+		case ssh.KindExit:
+			why = fmt.Sprintf("exit %d", res.ExitStatus)
+		case ssh.KindSignal:
+			why = "signal " + res.Signal
 		}
-	}
-	switch cmdName {
-	case "run":
-		cmdSize := len(args) - 1
-		cmds := make([]string, cmdSize)
-		for i := 0; i < cmdSize; i++ {
-			cmds[i] = strings.ToLower(args[i+1])
+		if why == status {
+			byStatus[status] = append(byStatus[status], res.Node.Ip)
+			continue
 		}
-		c, err := fetchCache(nil)
-		if err != nil {
-			fmt.Println("fetchCache :", err.Error())
-			return
+		byStatus[status] = append(byStatus[status], res.Node.Ip+"("+why+")")
+	}
+	statuses := []string{ssh.StatusSucceeded, ssh.StatusFailed, ssh.StatusUnreachable}
+	counts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		counts = append(counts, fmt.Sprintf("%d %s", len(byStatus[status]), status))
+	}
+	fmt.Fprintln(os.Stderr, strings.Join(counts, ","))
+	for _, status := range statuses[1:] {
+		if hosts := byStatus[status]; len(hosts) > 0 {
+			sort.Strings(hosts)
+			fmt.Fprintf(os.Stderr, "%s: %s\n", status, strings.Join(hosts, " "))
 		}
-		nodes := c.OrderNode()
-		exeCmd := strings.Join(cmds, " ")
+	}
+	switch {
+	case len(byStatus[ssh.StatusUnreachable]) > 0:
+		return &exitError{code: exitUnreachable}
+	case len(byStatus[ssh.StatusFailed]) > 0:
+		return &exitError{code: exitFailed}
+	}
+	return nil
+}
+
+// serverExecute runs cmd on nodes through the server,for nodes whose
+// credentials the server keeps to itself. Results arrive as hosts finish,
+// with fn set the output goes to fn line by line instead.
+func serverExecute(ctx context.Context, cli *conn.Conn, nodes []*meta.Node, cmd string, parallel, timeout int, fn ssh.LineFunc) <-chan *ssh.Result {
+	results := make(chan *ssh.Result, len(nodes))
+	go func() {
+		defer close(results)
+		byHost := make(map[string]*meta.Node, len(nodes))
+		hosts := make([]string, 0, len(nodes))
 		for _, node := range nodes {
-			output, err := ssh.Run(node, exeCmd)
-			fmt.Printf("********************%s***************************\n", node.Ip)
-			fmt.Printf("%s $ %s\n", node.Ip, exeCmd)
-			if err != nil {
-				fmt.Println(err)
-			} else {
-				fmt.Println(string(output))
-			}
+			byHost[node.Ip] = node
+			hosts = append(hosts, node.Ip)
 		}
-		break
-	case "load":
-		var resp *pb.UpdateResponse
-		if _, err := os.Stat(args[1]); os.IsNotExist(err) {
-			fmt.Println("load: ", args[1], " invalid")
-			return
+		fail := func(err error) {
+			kind := ssh.KindSession
+			switch {
+			case ctx.Err() != nil:
+				err, kind = ctx.Err(), ssh.KindCanceled
+			case conn.IsUnavailable(err):
+				kind = ssh.KindUnreachable
+			}
+			for _, node := range byHost {
+				results <- &ssh.Result{Node: node, Cmd: cmd, ExitStatus: -1, Kind: kind, Err: err}
+			}
 		}
-		if resp, err = cli.NewUpdateSession(args[1]); err != nil {
-			fmt.Println("new update session:", err)
+		stream, err := cli.NewExecSession(ctx, &pb.ExecRequest{
+			Hosts:    hosts,
+			Cmd:      cmd,
+			Parallel: int32(parallel),
+			Timeout:  int32(timeout),
+			Stream:   fn != nil,
+		})
+		if err != nil {
+			fail(err)
 			return
 		}
-		fmt.Fprintln(formatWriter, "host\tgroup\tmessage")
-		if len(resp.Response) > 0 {
-			sort.Slice(resp.Response, func(i, j int) bool {
-				if strings.Compare(resp.Response[i].Addr, resp.Response[j].Addr) < 0 {
-					return true
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				fail(errors.New("no result from server"))
+				return
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+			node, ok := byHost[resp.Host]
+			if !ok {
+				continue
+			}
+			if resp.Partial {
+				if fn != nil {
+					line, stderr := resp.Output, len(resp.Stderr) > 0
+					if stderr {
+						line = resp.Stderr
+					}
+					fn(node, stderr, bytes.TrimSuffix(line, []byte("\n")))
 				}
-				return false
-			})
-		}
-		for _, res := range resp.Response {
-			fmt.Fprintf(formatWriter, "%s\t%s\t%s\n", res.Addr, res.Group, res.Msg)
-		}
-		formatWriter.Flush()
-		break
-	case "delete":
-		var resp *pb.DeleteResponse
-		if resp, err = cli.NewDeleteSession(args[1:]); err != nil {
-			fmt.Println("new update session:", err)
-			return
-		}
-		fmt.Fprintln(formatWriter, "host\tgroup\tmessage")
-		sort.Slice(resp.Response, func(i, j int) bool {
-			if strings.Compare(resp.Response[i].Addr, resp.Response[j].Addr) < 0 {
-				return true
+				continue
 			}
-			return false
-
-		})
-		for _, res := range resp.Response {
-			fmt.Fprintf(formatWriter, "%s\t%s\t%s\n", res.Addr, res.Group, res.Msg)
-		}
-		formatWriter.Flush()
-		break
-	default:
-		usage()
-		break
-	}
-}
-func main() {
-	if len(os.Args) == 1 {
-		hanleOneCmd(nil)
-	} else if len(os.Args) == 2 {
-		if strings.Compare(strings.ToLower(os.Args[1]), "-h") == 0 || strings.Compare(strings.ToLower(os.Args[1]), "--help") == 0 {
-			usage()
-		} else {
-			hanleOneCmd(os.Args[1:])
+			delete(byHost, resp.Host)
+			res := &ssh.Result{
+				Node:       node,
+				Cmd:        cmd,
+				Stdout:     resp.Output,
+				Stderr:     resp.Stderr,
+				ExitStatus: int(resp.ExitStatus),
+				Signal:     resp.Signal,
+				Kind:       ssh.ErrorKind(resp.Kind),
+				Duration:   time.Duration(resp.Duration) * time.Millisecond,
+			}
+			if len(resp.Error) > 0 {
+				res.Err = errors.New(resp.Error)
+				if len(res.Kind) == 0 {
+					res.Kind = ssh.KindSession //from a server that predates kinds
+				}
+			}
+			results <- res
 		}
-	} else {
-		hanleMultiCmd(os.Args[1:])
-	}
+	}()
+	return results
 }
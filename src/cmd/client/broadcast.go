@@ -0,0 +1,137 @@
+package main
+
+import (
+	"broadcast"
+	"errors"
+	"fmt"
+	"io"
+	"meta"
+	"os"
+	"picker"
+	"ssh"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// maxBroadcastHosts keeps the panes of broadcast readable.
+const maxBroadcastHosts = 32
+
+// openShells starts a shell on each of nodes,through the server for nodes
+// without credentials. Nodes failing to open are left nil with their error.
+func openShells(o *targetOptions, nodes []*meta.Node, open func(node *meta.Node) (*ssh.Shell, error)) ([]*ssh.Shell, []error) {
+	shells, errs := make([]*ssh.Shell, len(nodes)), make([]error, len(nodes))
+	parallel := o.parallel
+	if parallel <= 0 {
+		parallel = ssh.DefaultParallel
+	}
+	slots := make(chan struct{}, parallel)
+	wg := &sync.WaitGroup{}
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node *meta.Node) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			shells[i], errs[i] = open(node)
+		}(i, node)
+	}
+	wg.Wait()
+	return shells, errs
+}
+
+func broadcastTo(o *targetOptions) error {
+	if !picker.IsTerminal() {
+		return errors.New("broadcast needs a terminal")
+	}
+	cli, access, err := connect()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	if !permitted(access, "login") {
+		return errors.New("permission denied: broadcast")
+	}
+	nodes, err := o.selectNodes()
+	if err != nil {
+		return err
+	}
+	if len(nodes) > maxBroadcastHosts {
+		return fmt.Errorf("%d nodes matched,broadcast takes at most %d", len(nodes), maxBroadcastHosts)
+	}
+	// the panes resize the ptys once they are laid out
+	timeout := time.Duration(o.timeout) * time.Second
+	shells, errs := openShells(o, nodes, func(node *meta.Node) (*ssh.Shell, error) {
+		if node.HasCredential() {
+			return ssh.OpenShell(node, 80, 24, timeout)
+		}
+		session, err := cli.NewProxySession(node.Ip)
+		if err != nil {
+			return nil, err
+		}
+		return ssh.StartShell(node, session, 80, 24)
+	})
+	targets := make([]*broadcast.Target, 0, len(shells))
+	direct := make([]string, 0, len(shells))
+	for i, shell := range shells {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", nodes[i].Ip, errs[i])
+			continue
+		}
+		shell := shell
+		defer shell.Close()
+		if shell.Node.HasCredential() {
+			direct = append(direct, shell.Node.Ip)
+		}
+		target := &broadcast.Target{
+			Name:   shell.Node.Ip,
+			Input:  shell.Stdin,
+			Output: shell.Output,
+			Resize: shell.Resize,
+		}
+		// every host is recorded as if logged in to alone
+		if rec := newRecorder(shell.Node.Ip); rec != nil {
+			defer rec.Close()
+			target.Output = io.TeeReader(shell.Output, rec)
+			target.Resize = func(width, height int) error {
+				rec.Resize(width, height)
+				return shell.Resize(width, height)
+			}
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return errors.New("no shell opened")
+	}
+	if len(direct) > 0 {
+		report(cli, "broadcast", direct, fmt.Sprintf("%d opened", len(direct)), "direct")
+	}
+	return broadcast.Start(targets)
+}
+
+func newBroadcastCmd() *cobra.Command {
+	o := &targetOptions{}
+	cmd := &cobra.Command{
+		Use:   "broadcast [flags]",
+		Short: "type into shells on many nodes at once",
+		Long: `broadcast opens a shell on each selected node and sends every keystroke to all
+of them,like synchronized tmux panes. The shells are shown in panes,or one at a
+time in the focus view where full screen programs work. Commands start with
+ctrl-]:
+  f,enter  switch between the panes and the focus view
+  n,tab,p  select the next or previous host
+  t,space  toggle the selected host in or out of the broadcast
+  a        broadcast to all hosts again
+  o        broadcast to the selected host only
+  q        quit and close every shell
+  ctrl-]   send ctrl-] itself
+--timeout bounds connecting to each node.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastTo(o)
+		},
+	}
+	o.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,748 @@
+package main
+
+import (
+	"cache"
+	"errors"
+	"fmt"
+	"meta"
+	"os"
+	"output"
+	"pb"
+	"picker"
+	"record"
+	"sort"
+	"ssh"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"utils"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+// orderedNodes fetches the nodes the user may reach in group order.
+func orderedNodes() ([]*meta.Node, error) {
+	cli, _, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	cli.Close()
+	c, err := fetchCache(nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch cache: %v", err)
+	}
+	if len(c.GroupRefNodes) == 0 {
+		return nil, errors.New("empty nodes")
+	}
+	return c.OrderNode(), nil
+}
+
+func printNodes(nodes []*meta.Node) error {
+	return printRecords([]string{"host", "tag", "group"}, nodeRecords(nodes))
+}
+
+func listNodes() error {
+	nodes, err := orderedNodes()
+	if err != nil {
+		return err
+	}
+	return printNodes(nodes)
+}
+
+// pickNode lets the user choose a node to login to on a terminal,the node
+// table is printed instead when stdin or stdout is not one or -o is given.
+func pickNode() error {
+	nodes, err := orderedNodes()
+	if err != nil {
+		return err
+	}
+	if !picker.IsTerminal() || len(outputFlag) > 0 {
+		return printNodes(nodes)
+	}
+	node, err := picker.Pick(nodes)
+	if err == picker.CanceledErr {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return login(node.Ip)
+}
+
+func listGroups() error {
+	cli, _, err := connect()
+	if err != nil {
+		return err
+	}
+	cli.Close()
+	c, err := fetchCache(nil)
+	if err != nil {
+		return fmt.Errorf("fetch cache: %v", err)
+	}
+	if len(c.GroupCache) == 0 {
+		return errors.New("empty group")
+	}
+	records := make([]output.Record, 0, len(c.GroupCache))
+	for _, groupName := range c.OrderGroup() {
+		records = append(records, &groupRecord{Group: groupName, Nodes: c.GroupCache[groupName]})
+	}
+	return printRecords([]string{"nodes", "group"}, records)
+}
+
+// login opens a terminal to ip,relayed by the server when the node came
+// without credentials.
+func login(ip string) error {
+	cli, access, err := connect()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	if !permitted(access, "login") {
+		return errors.New("permission denied: login")
+	}
+	c, err := fetchCache(nil)
+	if err != nil {
+		return fmt.Errorf("go host: %v", err)
+	}
+	node, ok := c.NodeCache[ip]
+	if !ok {
+		return fmt.Errorf("unknown node: %s", ip)
+	}
+	if !node.HasCredential() {
+		session, err := cli.NewProxySession(ip)
+		if err != nil {
+			return fmt.Errorf("proxy %s: %v", ip, err)
+		}
+		rec := newRecorder(ip)
+		if rec != nil {
+			defer rec.Close()
+		}
+		if err = ssh.NewTerminal(session, rec); err != nil {
+			return fmt.Errorf("proxy %s: %v", ip, err)
+		}
+		return nil
+	}
+	rec := newRecorder(ip)
+	detail := "direct"
+	if rec != nil {
+		defer rec.Close()
+		detail = "direct,record " + rec.Path()
+	}
+	report(cli, "login", []string{ip}, "ok", detail)
+	if err = ssh.NewSSHConnection(node, rec); err != nil {
+		report(cli, "logout", []string{ip}, err.Error(), detail)
+		return fmt.Errorf("connect %s:%d: %v", node.Ip, node.Port, err)
+	}
+	report(cli, "logout", []string{ip}, "ok", detail)
+	return nil
+}
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "list [node|group]",
+		Aliases:   []string{"ls"},
+		Short:     "list nodes or node groups",
+		Args:      cobra.OnlyValidArgs,
+		ValidArgs: []string{"node", "group"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && args[0] == "group" {
+				return listGroups()
+			}
+			return listNodes()
+		},
+	}
+}
+
+func newGroupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "group",
+		Short: "list node groups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listGroups()
+		},
+	}
+}
+
+func newGoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "go {ip}",
+		Aliases:           []string{"ssh"},
+		Short:             "login to a node,same as vsh {ip}",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(hostCandidates, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return login(args[0])
+		},
+	}
+}
+
+func newUserCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "user",
+		Short: "list users and their roles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, _, err := connect()
+			if err != nil {
+				return err
+			}
+			defer cli.Close()
+			resp, err := cli.NewUserSession()
+			if err != nil {
+				return err
+			}
+			userNames := make([]string, 0, len(resp.Response))
+			for userName := range resp.Response {
+				userNames = append(userNames, userName)
+			}
+			sort.Strings(userNames)
+			records := make([]output.Record, 0, len(userNames))
+			for _, userName := range userNames {
+				records = append(records, &userRecord{User: userName, Type: resp.Response[userName], Roles: cache.SplitList(resp.Roles[userName])})
+			}
+			return printRecords([]string{"user", "type", "roles"}, records)
+		},
+	}
+}
+
+func newTemplateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "template",
+		Short: "create " + defaultClusterTemplateFile + " to fill in and load",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stat(defaultClusterTemplateFile); !os.IsNotExist(err) {
+				fmt.Println("template exists in ", defaultClusterTemplateFile)
+				return nil
+			}
+			utils.CreateTemplate(defaultClusterTemplateFile)
+			fmt.Println("create template success")
+			return nil
+		},
+	}
+}
+
+func newDumpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dump",
+		Short: "dump cluster info on the server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, _, err := connect()
+			if err != nil {
+				return err
+			}
+			defer cli.Close()
+			if _, err = cli.NewDumpSession(); err != nil {
+				return err
+			}
+			fmt.Println("dump success on remote!")
+			return nil
+		},
+	}
+}
+
+func printResponses(responses []*pb.Response) error {
+	return printRecords([]string{"host", "group", "message"}, responseRecords(responses))
+}
+
+func newLoadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "load {file}",
+		Short: "load nodes from a cluster file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stat(args[0]); os.IsNotExist(err) {
+				return fmt.Errorf("load: %s invalid", args[0])
+			}
+			cli, _, err := connect()
+			if err != nil {
+				return err
+			}
+			defer cli.Close()
+			removeCache()
+			resp, err := cli.NewUpdateSession(args[0])
+			if err != nil {
+				return fmt.Errorf("new update session: %v", err)
+			}
+			return printResponses(resp.Response)
+		},
+	}
+}
+
+func newDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete {group...}",
+		Short:             "delete nodes of groups",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeArgs(groupCandidates, 0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, _, err := connect()
+			if err != nil {
+				return err
+			}
+			defer cli.Close()
+			removeCache()
+			resp, err := cli.NewDeleteSession(args)
+			if err != nil {
+				return fmt.Errorf("new delete session: %v", err)
+			}
+			return printResponses(resp.Response)
+		},
+	}
+}
+
+func newHostKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "hostkey {ip...}",
+		Short:             "accept the rotated host key of nodes",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeArgs(hostCandidates, 0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, _, err := connect()
+			if err != nil {
+				return err
+			}
+			defer cli.Close()
+			removeCache()
+			resp, err := cli.NewHostKeySession(args)
+			if err != nil {
+				return fmt.Errorf("new hostkey session: %v", err)
+			}
+			return printResponses(resp.Response)
+		},
+	}
+}
+
+// targetOptions picks the nodes of run,push and pull.
+type targetOptions struct {
+	parallel int
+	timeout  int
+	groups   string
+	tags     string
+	hosts    string
+	excludes string
+}
+
+func (o *targetOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&o.parallel, "parallel", "p", ssh.DefaultParallel, "max hosts working at the same time")
+	cmd.Flags().IntVar(&o.timeout, "timeout", 0, "per-host timeout in seconds, 0 means wait forever")
+	cmd.Flags().StringVarP(&o.groups, "groups", "g", "", "comma separated node groups")
+	cmd.Flags().StringVarP(&o.tags, "tags", "t", "", "comma separated node tags")
+	cmd.Flags().StringVarP(&o.hosts, "hosts", "H", "", "comma separated ip, cidr or glob")
+	cmd.Flags().StringVar(&o.excludes, "exclude", "", "comma separated ip, cidr or glob to skip")
+	completeFlags(cmd, map[string]candidates{
+		"groups":  groupCandidates,
+		"tags":    tagCandidates,
+		"hosts":   hostCandidates,
+		"exclude": hostCandidates,
+	})
+}
+
+func (o *targetOptions) selectNodes() ([]*meta.Node, error) {
+	selector := &cache.Selector{
+		Groups:   cache.SplitList(strings.ToLower(o.groups)),
+		Tags:     cache.SplitList(strings.ToLower(o.tags)),
+		Hosts:    cache.SplitList(o.hosts),
+		Excludes: cache.SplitList(o.excludes),
+	}
+	c, err := fetchCache(selector.Groups)
+	if err != nil {
+		return nil, fmt.Errorf("fetch cache: %v", err)
+	}
+	nodes := c.Select(selector)
+	if len(nodes) == 0 {
+		return nil, errors.New("no node matched")
+	}
+	return nodes, nil
+}
+
+func (o *targetOptions) executor() *ssh.Executor {
+	return ssh.NewExecutor(o.parallel, time.Duration(o.timeout)*time.Second)
+}
+
+type runOptions struct {
+	targetOptions
+	ordered bool
+	stream  bool
+}
+
+func (o *runOptions) addFlags(cmd *cobra.Command) {
+	o.targetOptions.addFlags(cmd)
+	cmd.Flags().BoolVar(&o.ordered, "sort", false, "print results ordered by group and ip after all hosts finish")
+	cmd.Flags().BoolVar(&o.stream, "stream", false, "print output lines as they arrive prefixed by host,ctrl-c stops")
+}
+
+func newRunCmd() *cobra.Command {
+	o := &runOptions{}
+	cmd := &cobra.Command{
+		Use:   "run [flags] {cmd...}",
+		Short: "execute a shell command on many nodes",
+		Long: `run executes a shell command on the selected nodes and ends with a summary of
+the hosts that succeeded,failed or were unreachable. vsh exits with 2 when some
+host failed and 3 when some host was unreachable.
+A single argument is run as a shell command line,quote the whole command to use
+pipes,globs or ~ on the nodes. Several arguments are passed word by word as typed,
+so vsh run -g web grep "a b" f greps for a b.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(o, args)
+		},
+	}
+	// flags end at the command,so vsh run -g web ls -l passes -l to ls
+	cmd.Flags().SetInterspersed(false)
+	o.addFlags(cmd)
+	return cmd
+}
+
+func run(o *runOptions, args []string) error {
+	return execute(o, "run", commandLine(args), "")
+}
+
+// commandLine makes the command for the remote shell of the arguments of run.
+// One argument is a command line of its own,several are words and quoted when
+// they hold more than plain characters.
+func commandLine(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	words := make([]string, 0, len(args))
+	for _, arg := range args {
+		if len(arg) == 0 || strings.IndexFunc(arg, needsQuote) >= 0 {
+			arg = ssh.ShellQuote(arg)
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+func needsQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("@%+=:,./_-", r)
+}
+
+// execute runs exeCmd on the selected nodes and reports it as action,with
+// detail in the audit log or the command when detail is empty.
+func execute(o *runOptions, action, exeCmd, detail string) error {
+	if o.stream && (o.ordered || len(outputFlag) > 0) {
+		return errors.New("--stream prints lines as they arrive,it can not be used with --sort or -o")
+	}
+	cli, access, err := connect()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	if !permitted(access, "run") {
+		return fmt.Errorf("permission denied: %s", action)
+	}
+	nodes, err := o.selectNodes()
+	if err != nil {
+		return err
+	}
+	if len(detail) == 0 {
+		detail = exeCmd
+	}
+	// nodes handed out without credentials run through the server,which
+	// enforces the command policies and audits them itself
+	direct, proxied := make([]*meta.Node, 0, len(nodes)), make([]*meta.Node, 0)
+	for _, node := range nodes {
+		if node.HasCredential() {
+			direct = append(direct, node)
+		} else {
+			proxied = append(proxied, node)
+		}
+	}
+	// --stream hands each line to the printer and stops on ctrl-c
+	ctx := context.Background()
+	var printer *streamPrinter
+	var onLine ssh.LineFunc
+	if o.stream {
+		var stop func()
+		ctx, stop = interruptContext()
+		defer stop()
+		printer = newStreamPrinter(nodes, useColor())
+		onLine = printer.line
+	}
+	merged := make(chan *ssh.Result)
+	wg := &sync.WaitGroup{}
+	forward := func(in <-chan *ssh.Result) {
+		defer wg.Done()
+		for res := range in {
+			merged <- res
+		}
+	}
+	if len(direct) > 0 {
+		wg.Add(1)
+		if o.stream {
+			go forward(o.executor().Stream(ctx, direct, exeCmd, onLine))
+		} else {
+			go forward(o.executor().Execute(direct, exeCmd))
+		}
+	}
+	if len(proxied) > 0 {
+		wg.Add(1)
+		go forward(serverExecute(ctx, cli, proxied, exeCmd, o.parallel, o.timeout, onLine))
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+	// -o prints every result at once after all hosts finish,ordered
+	ordered := o.ordered || len(outputFlag) > 0
+	results := make([]*ssh.Result, 0, len(nodes))
+	failed := 0
+	for res := range merged {
+		res.Cmd = detail //what was asked for,not the wrapper of a script
+		if res.Err != nil && res.Node.HasCredential() {
+			failed++
+		}
+		results = append(results, res)
+		switch {
+		case printer != nil:
+			printer.result(res)
+		case !ordered:
+			printRunResult(res)
+		}
+	}
+	if ordered {
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Node.GroupName != results[j].Node.GroupName {
+				return strings.Compare(results[i].Node.GroupName, results[j].Node.GroupName) < 0
+			}
+			return strings.Compare(results[i].Node.Ip, results[j].Node.Ip) < 0
+		})
+	}
+	if len(outputFlag) > 0 {
+		records := make([]output.Record, 0, len(results))
+		for _, res := range results {
+			r := newRunRecord(res)
+			if outputFormat() == output.Table {
+				r = r.summary()
+			}
+			records = append(records, r)
+		}
+		err = printRecords(runHeader, records)
+	} else if o.ordered {
+		for _, res := range results {
+			printRunResult(res)
+		}
+	}
+	if len(direct) > 0 {
+		targets := make([]string, 0, len(direct))
+		for _, node := range direct {
+			targets = append(targets, node.Ip)
+		}
+		report(cli, action, targets, fmt.Sprintf("%d ok,%d failed", len(direct)-failed, failed), detail)
+	}
+	if err != nil {
+		return err
+	}
+	return summarize(results)
+}
+
+func newAuditCmd() *cobra.Command {
+	var since, until string
+	req := &pb.AuditRequest{}
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "show the audit log,super users see everyone",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, item := range []struct {
+				value string
+				unix  *int64
+			}{{since, &req.Since}, {until, &req.Until}} {
+				t, err := parseTime(item.value)
+				if err != nil {
+					return err
+				}
+				if !t.IsZero() {
+					*item.unix = t.Unix()
+				}
+			}
+			cli, err := initConn(configFile)
+			if err != nil {
+				return fmt.Errorf("init connection: %v", err)
+			}
+			defer cli.Close()
+			resp, err := cli.NewAuditSession(req)
+			if err != nil {
+				return err
+			}
+			records := make([]output.Record, 0, len(resp.Entries))
+			for _, e := range resp.Entries {
+				records = append(records, &auditRecord{Time: time.Unix(e.Time, 0), User: e.User, Addr: e.Addr,
+					Action: e.Action, Targets: e.Targets, Result: e.Result, Detail: e.Detail})
+			}
+			return printRecords([]string{"time", "user", "addr", "action", "targets", "result", "detail"}, records)
+		},
+	}
+	cmd.Flags().StringVarP(&req.User, "user", "u", "", "only entries of this user")
+	cmd.Flags().StringVarP(&req.Node, "node", "n", "", "only entries touching this node")
+	cmd.Flags().StringVar(&since, "since", "", "start time,such as 24h or 2019-01-02 15:04")
+	cmd.Flags().StringVar(&until, "until", "", "end time,such as 1h or 2019-01-02 15:04")
+	cmd.Flags().Int32Var(&req.Limit, "limit", 100, "newest entries to show")
+	completeFlags(cmd, map[string]candidates{"node": hostCandidates})
+	return cmd
+}
+
+func newReplayCmd() *cobra.Command {
+	var speed, idle float64
+	cmd := &cobra.Command{
+		Use:   "replay {file}",
+		Short: "play a recorded session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			header, err := record.Play(file, os.Stdout, speed, time.Duration(idle*float64(time.Second)))
+			if err != nil {
+				fmt.Println()
+				return err
+			}
+			fmt.Printf("\n[%s on %s,recorded %s]\n", header.User, header.Node, time.Unix(header.Timestamp, 0).Format(time.RFC3339))
+			return nil
+		},
+	}
+	cmd.Flags().Float64Var(&speed, "speed", 1, "playback speed,2 plays twice as fast")
+	cmd.Flags().Float64Var(&idle, "idle", 2, "longest pause in seconds")
+	return cmd
+}
+
+type grantOptions struct {
+	user     string
+	groups   string
+	tags     string
+	hosts    string
+	roles    string
+	duration time.Duration
+	reason   string
+}
+
+func (o *grantOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.groups, "groups", "g", "", "comma separated node groups")
+	cmd.Flags().StringVarP(&o.tags, "tags", "t", "", "comma separated node tags")
+	cmd.Flags().StringVarP(&o.hosts, "hosts", "H", "", "comma separated ip, cidr or glob")
+	cmd.Flags().StringVarP(&o.roles, "roles", "r", "", "comma separated roles")
+	cmd.Flags().DurationVarP(&o.duration, "duration", "d", 4*time.Hour, "how long the grant lasts")
+	cmd.Flags().StringVar(&o.reason, "reason", "", "why access is needed")
+	completeFlags(cmd, map[string]candidates{
+		"groups": groupCandidates,
+		"tags":   tagCandidates,
+		"hosts":  hostCandidates,
+	})
+}
+
+func (o *grantOptions) request(action string) *pb.GrantRequest {
+	return &pb.GrantRequest{
+		Action:    action,
+		User:      o.user,
+		Groups:    cache.SplitList(strings.ToLower(o.groups)),
+		Tags:      cache.SplitList(strings.ToLower(o.tags)),
+		Addresses: cache.SplitList(o.hosts),
+		Roles:     cache.SplitList(o.roles),
+		Duration:  int64(o.duration / time.Second),
+		Reason:    o.reason,
+	}
+}
+
+func sendGrant(req *pb.GrantRequest, requestAccess bool) error {
+	cli, err := initConn(configFile)
+	if err != nil {
+		return fmt.Errorf("init connection: %v", err)
+	}
+	defer cli.Close()
+	var resp *pb.GrantResponse
+	if requestAccess {
+		resp, err = cli.NewRequestAccessSession(req)
+	} else {
+		resp, err = cli.NewGrantSession(req)
+	}
+	if err != nil {
+		return err
+	}
+	return printGrants(resp.Grants)
+}
+
+func newGrantIdCmd(action, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   action + " {id}",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid id %s", args[0])
+			}
+			return sendGrant(&pb.GrantRequest{Action: action, Id: id}, false)
+		},
+	}
+}
+
+// grantFlags are the flags of grant that make it add a grant,the inherited
+// ones such as -o and --context do not.
+var grantFlags = []string{"user", "groups", "tags", "hosts", "roles", "duration", "reason"}
+
+func addsGrant(cmd *cobra.Command) bool {
+	for _, name := range grantFlags {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// newGrantCmd lists grants when called without grant flags,with them it adds
+// a grant that starts right away.
+func newGrantCmd() *cobra.Command {
+	o := &grantOptions{}
+	cmd := &cobra.Command{
+		Use:   "grant",
+		Short: "list or add temporary grants,adding needs super",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !addsGrant(cmd) {
+				return sendGrant(&pb.GrantRequest{Action: "list"}, false)
+			}
+			return sendGrant(o.request("add"), false)
+		},
+	}
+	cmd.Flags().StringVarP(&o.user, "user", "u", "", "user to grant,defaults to yourself")
+	o.addFlags(cmd)
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "list grants,super users see all",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return sendGrant(&pb.GrantRequest{Action: "list"}, false)
+			},
+		},
+		newGrantIdCmd("approve", "approve a requested grant,its time starts now"),
+		newGrantIdCmd("revoke", "drop a grant"),
+	)
+	return cmd
+}
+
+func newRequestAccessCmd() *cobra.Command {
+	o := &grantOptions{}
+	cmd := &cobra.Command{
+		Use:   "request-access",
+		Short: "ask a super user for temporary access",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return sendGrant(o.request("add"), true)
+		},
+	}
+	o.addFlags(cmd)
+	return cmd
+}
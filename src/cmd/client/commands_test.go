@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAddsGrant(t *testing.T) {
+	cases := []struct {
+		args string
+		adds bool
+	}{
+		{"grant", false},
+		{"grant -o json", false},
+		{"--context prod grant", false},
+		{"grant -g prod", true},
+		{"grant -u alice -d 1h -o yaml", true},
+		{"grant --reason incident", true},
+	}
+	// the persistent flags set globals
+	defer func(context, output string) { contextName, outputFlag = context, output }(contextName, outputFlag)
+	for _, c := range cases {
+		root := newRootCmd()
+		cmd, args, err := root.Find(strings.Fields(c.args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = cmd.ParseFlags(args); err != nil {
+			t.Fatal(c.args, ": ", err)
+		}
+		if adds := addsGrant(cmd); adds != c.adds {
+			t.Errorf("%s expect adding %v,got %v", c.args, c.adds, adds)
+		}
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	cases := []struct {
+		args   []string
+		output string
+	}{
+		{[]string{"echo a b | tr a-z A-Z"}, "A B\n"},
+		{[]string{"printf", "%s|", "a b", "it's", "$HOME", "*", ""}, "a b|it's|$HOME|*||"},
+		{[]string{"echo", "-n", "x=1,y=2/3"}, "x=1,y=2/3"},
+	}
+	for _, c := range cases {
+		cmd := commandLine(c.args)
+		out, err := exec.Command("sh", "-c", cmd).Output()
+		if err != nil || string(out) != c.output {
+			t.Errorf("%q as %s expect %q,got %q %v", c.args, cmd, c.output, out, err)
+		}
+	}
+}
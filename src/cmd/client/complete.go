@@ -0,0 +1,92 @@
+package main
+
+import (
+	"cache"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// candidates lists cache entries starting with a prefix,see cache.HostCompletions.
+type candidates func(c *cache.Cache, prefix string) []string
+
+func hostCandidates(c *cache.Cache, prefix string) []string  { return c.HostCompletions(prefix) }
+func groupCandidates(c *cache.Cache, prefix string) []string { return c.GroupCompletions(prefix) }
+func tagCandidates(c *cache.Cache, prefix string) []string   { return c.TagCompletions(prefix) }
+
+// localCache reads the cache file without asking the server,so completion
+// stays fast and works offline. An expired cache still completes.
+func localCache() *cache.Cache {
+	_, keyFile := cacheFiles()
+	if len(keyFile) == 0 {
+		return nil
+	}
+	key, err := cache.LoadKey(keyFile)
+	if err != nil {
+		return nil
+	}
+	c, err := readCache(key)
+	if err != nil {
+		return nil
+	}
+	return c
+}
+
+// completeArgs completes up to max positional args,0 means any number,and
+// leaves out values already given.
+func completeArgs(list candidates, max int) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if max > 0 && len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		c := localCache()
+		if c == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		given := make(map[string]bool)
+		for _, arg := range args {
+			given[strings.ToLower(arg)] = true
+		}
+		res := make([]string, 0)
+		for _, item := range list(c, toComplete) {
+			if value := strings.SplitN(item, "\t", 2)[0]; !given[strings.ToLower(value)] {
+				res = append(res, item)
+			}
+		}
+		return res, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeList completes the last item of a comma separated flag value.
+func completeList(list candidates) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		c := localCache()
+		if c == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		prefix, last := "", toComplete
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix, last = toComplete[:i+1], toComplete[i+1:]
+		}
+		given := make(map[string]bool)
+		for _, item := range cache.SplitList(strings.ToLower(prefix)) {
+			given[item] = true
+		}
+		res := make([]string, 0)
+		for _, item := range list(c, last) {
+			if value := strings.SplitN(item, "\t", 2)[0]; !given[strings.ToLower(value)] {
+				res = append(res, prefix+item)
+			}
+		}
+		return res, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFlags completes the named flags of cmd from the cache.
+func completeFlags(cmd *cobra.Command, flags map[string]candidates) {
+	for name, list := range flags {
+		cmd.RegisterFlagCompletionFunc(name, completeList(list))
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"output"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"utils"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultContext names the server written at the top level of the config,
+// its cache keeps the names from before contexts.
+const defaultContext = "default"
+
+var contextNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// contextName picks the server profile of this run,set by --context. Empty
+// means the current context of the config.
+var contextName string
+
+// ClientConfig is the whole config file. A file with only addr,port and so on
+// is a single profile named default.
+type ClientConfig struct {
+	Config
+	Current  string             `json:"current,omitempty"`
+	Contexts map[string]*Config `json:"contexts,omitempty"`
+}
+
+// Names lists the profiles,default first when the top level names a server.
+func (c *ClientConfig) Names() []string {
+	names := make([]string, 0, len(c.Contexts)+1)
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if _, ok := c.Contexts[defaultContext]; !ok && len(c.Addr) > 0 {
+		names = append([]string{defaultContext}, names...)
+	}
+	return names
+}
+
+// Profile returns the server config of name.
+func (c *ClientConfig) Profile(name string) (*Config, error) {
+	if conf, ok := c.Contexts[name]; ok && conf != nil {
+		return conf, nil
+	}
+	if name == defaultContext && len(c.Addr) > 0 {
+		return &c.Config, nil
+	}
+	return nil, fmt.Errorf("unknown context %s", name)
+}
+
+// CurrentName is the context used without --context. When the config does
+// not say it is the top level server,or the only context.
+func (c *ClientConfig) CurrentName() (string, error) {
+	if len(c.Current) > 0 {
+		return c.Current, nil
+	}
+	names := c.Names()
+	switch {
+	case len(names) == 0:
+		return "", errors.New("no server in config")
+	case len(c.Addr) > 0:
+		return defaultContext, nil
+	case len(names) == 1:
+		return names[0], nil
+	}
+	return "", errors.New("no current context,see vsh context use")
+}
+
+func loadClientConfig(path string) (*ClientConfig, error) {
+	rootPath, err := utils.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	var conf ClientConfig
+	if err = json.Unmarshal(b, &conf); err != nil {
+		return nil, err
+	}
+	for name := range conf.Contexts {
+		if !contextNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid context name %q", name)
+		}
+	}
+	return &conf, nil
+}
+
+// profile is the context of a run and its server config as loaded from path.
+type profile struct {
+	path    string
+	context string //--context it was loaded with
+	name    string
+	conf    *Config
+	err     error
+}
+
+var (
+	profileMutex = &sync.Mutex{}
+	loaded       *profile
+)
+
+// loadProfile returns the context of this run and its server config,the
+// config is read once per run.
+func loadProfile(path string) (string, *Config, error) {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+	if loaded == nil || loaded.path != path || loaded.context != contextName {
+		loaded = &profile{path: path, context: contextName}
+		loaded.name, loaded.conf, loaded.err = readProfile(path)
+	}
+	return loaded.name, loaded.conf, loaded.err
+}
+
+func readProfile(path string) (string, *Config, error) {
+	conf, err := loadClientConfig(path)
+	if err != nil {
+		return "", nil, err
+	}
+	name := contextName
+	if len(name) == 0 {
+		if name, err = conf.CurrentName(); err != nil {
+			return "", nil, err
+		}
+	}
+	profile, err := conf.Profile(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, profile, nil
+}
+
+// cacheFiles are the cache file of the context,relative to home,and its key
+// file,so switching never mixes node lists. The key is kept apart from the
+// cache in $XDG_RUNTIME_DIR,or in the vsh dir of the user config dir when it
+// is unset. The key file is empty when neither is known. An unreadable config
+// falls back to the default context.
+func cacheFiles() (string, string) {
+	cacheFile, keyFile := defaultCacheClusterFile, defaultCacheKeyFile
+	if name, _, err := loadProfile(configFile); err == nil && name != defaultContext {
+		cacheFile, keyFile = fmt.Sprintf(".vsh_cache.%s.json", name), fmt.Sprintf("vsh_cache.%s.key", name)
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); len(runtimeDir) > 0 {
+		return cacheFile, filepath.Join(runtimeDir, keyFile)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return cacheFile, ""
+	}
+	return cacheFile, filepath.Join(configDir, "vsh", keyFile)
+}
+
+// useContext makes name the current context,other keys of the file are kept.
+func useContext(path, name string) error {
+	conf, err := loadClientConfig(path)
+	if err != nil {
+		return err
+	}
+	if _, err = conf.Profile(name); err != nil {
+		return err
+	}
+	rootPath, err := utils.Expand(path)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(rootPath)
+	if err != nil {
+		return err
+	}
+	raw := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw["current"], err = json.Marshal(name); err != nil {
+		return err
+	}
+	if b, err = json.MarshalIndent(raw, "", "    "); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(rootPath, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	profileMutex.Lock()
+	loaded = nil
+	profileMutex.Unlock()
+	return nil
+}
+
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	conf, err := loadClientConfig(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	res := make([]string, 0)
+	for _, name := range conf.Names() {
+		if profile, _ := conf.Profile(name); profile != nil {
+			res = append(res, fmt.Sprintf("%s\t%s:%d", name, profile.Addr, profile.Port))
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+func listContexts() error {
+	conf, err := loadClientConfig(configFile)
+	if err != nil {
+		return err
+	}
+	current := contextName
+	if len(current) == 0 {
+		current, _ = conf.CurrentName()
+	}
+	records := make([]output.Record, 0, len(conf.Contexts)+1)
+	for _, name := range conf.Names() {
+		profile, _ := conf.Profile(name)
+		records = append(records, &contextRecord{Name: name, Server: fmt.Sprintf("%s:%d", profile.Addr, profile.Port), Current: name == current})
+	}
+	return printRecords([]string{"current", "name", "server"}, records)
+}
+
+func newContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "list the server profiles of the config",
+		Long: `context lists the server profiles of the config,named under "contexts".
+The current one is used unless --context names another,each keeps its own cache.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listContexts()
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list the server profiles",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listContexts()
+		},
+	}, &cobra.Command{
+		Use:   "current",
+		Short: "print the context in use",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _, err := loadProfile(configFile)
+			if err != nil {
+				return err
+			}
+			fmt.Println(name)
+			return nil
+		},
+	}, &cobra.Command{
+		Use:               "use {name}",
+		Short:             "make a server profile the current one",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContexts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := useContext(configFile, args[0]); err != nil {
+				return err
+			}
+			fmt.Println("switched to context", args[0])
+			return nil
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestContextNames(t *testing.T) {
+	cases := []struct {
+		conf    string
+		names   []string
+		current string //empty when there is none
+		addr    string //of the current context
+	}{
+		{`{"addr":"10.0.0.1","port":5566}`, []string{"default"}, "default", "10.0.0.1"},
+		{`{"addr":"10.0.0.1","contexts":{"prod":{"addr":"10.0.0.2"}}}`, []string{"default", "prod"}, "default", "10.0.0.1"},
+		// a context named default wins over the top level server
+		{`{"addr":"10.0.0.1","contexts":{"default":{"addr":"10.0.0.3"},"alpha":{"addr":"10.0.0.4"}}}`, []string{"alpha", "default"}, "default", "10.0.0.3"},
+		{`{"contexts":{"prod":{"addr":"10.0.0.2"}}}`, []string{"prod"}, "prod", "10.0.0.2"},
+		{`{"contexts":{"prod":{"addr":"10.0.0.2"},"staging":{"addr":"10.0.1.2"}}}`, []string{"prod", "staging"}, "", ""},
+		{`{"current":"staging","contexts":{"prod":{"addr":"10.0.0.2"},"staging":{"addr":"10.0.1.2"}}}`, []string{"prod", "staging"}, "staging", "10.0.1.2"},
+		{`{}`, []string{}, "", ""},
+	}
+	for _, c := range cases {
+		var conf ClientConfig
+		if err := json.Unmarshal([]byte(c.conf), &conf); err != nil {
+			t.Fatal(err)
+		}
+		if names := conf.Names(); !reflect.DeepEqual(names, c.names) {
+			t.Errorf("%s expect names %v,got %v", c.conf, c.names, names)
+		}
+		current, err := conf.CurrentName()
+		if (err == nil) != (len(c.current) > 0) || current != c.current {
+			t.Errorf("%s expect current %q,got %q %v", c.conf, c.current, current, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if profile, err := conf.Profile(current); err != nil || profile.Addr != c.addr {
+			t.Errorf("%s expect server %s,got %v %v", c.conf, c.addr, profile, err)
+		}
+	}
+}
+
+func TestUseContext(t *testing.T) {
+	file, err := ioutil.TempFile("", "vsh_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(`{"addr":"10.0.0.1","record_dir":"off","contexts":{"prod":{"addr":"10.0.0.2"}}}`)
+	file.Close()
+	if name, _, err := loadProfile(file.Name()); err != nil || name != defaultContext {
+		t.Fatal("expect default context,got ", name, err)
+	}
+	if err = useContext(file.Name(), "staging"); err == nil {
+		t.Fatal("switched to an unknown context")
+	}
+	if err = useContext(file.Name(), "prod"); err != nil {
+		t.Fatal(err)
+	}
+	name, profile, err := loadProfile(file.Name())
+	if err != nil || name != "prod" || profile.Addr != "10.0.0.2" {
+		t.Fatal("expect prod after use,got ", name, profile, err)
+	}
+	conf, err := loadClientConfig(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Current != "prod" || conf.RecordDir != "off" || conf.Addr != "10.0.0.1" {
+		t.Fatal("other keys of the config changed ", conf)
+	}
+	if err = useContext(file.Name(), defaultContext); err != nil {
+		t.Fatal(err)
+	}
+	if name, _, _ = loadProfile(file.Name()); name != defaultContext {
+		t.Fatal("expect default after use,got ", name)
+	}
+}
+
+// the key stays apart from the cache also without $XDG_RUNTIME_DIR,such as on
+// macOS or under sudo.
+func TestCacheKeyFile(t *testing.T) {
+	for _, name := range []string{"XDG_RUNTIME_DIR", "XDG_CONFIG_HOME"} {
+		defer os.Setenv(name, os.Getenv(name))
+	}
+	defer func(path string) { configFile = path }(configFile)
+	configFile = "/nonexistent/vsh_config.json"
+	os.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if _, keyFile := cacheFiles(); keyFile != "/run/user/1000/vsh_cache.key" {
+		t.Fatal("expect the key in the runtime dir,got ", keyFile)
+	}
+	os.Unsetenv("XDG_RUNTIME_DIR")
+	os.Setenv("XDG_CONFIG_HOME", "/home/alice/.config")
+	if _, keyFile := cacheFiles(); keyFile != "/home/alice/.config/vsh/vsh_cache.key" {
+		t.Fatal("expect the key in the config dir,got ", keyFile)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"utils"
+
+	"github.com/spf13/cobra"
+)
+
+// configFile holds the server profiles,set by --config.
+var configFile = "~/" + defaultClusterServerConfigFile
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "vsh [ip]",
+		Short: "ssh to the nodes kept by vsh_server",
+		Long: `vsh opens a picker of the nodes you may reach when called alone and
+logs in to a node when called with its ip. Type to filter the nodes by ip,tag
+or group,move with up/down or ctrl-p/ctrl-n,enter to login and esc to quit.
+Without a terminal the nodes are printed as a table.
+
+The server is the current context of ~/` + defaultClusterServerConfigFile + `,--context picks
+another one for a single run and --config another file.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(hostCandidates, 1),
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return pickNode()
+			}
+			if !utils.ValidIpAddr(args[0]) {
+				return fmt.Errorf("unknown command or node %q,see vsh --help", args[0])
+			}
+			return login(args[0])
+		},
+	}
+	root.PersistentFlags().StringVar(&configFile, "config", configFile, "config file of the servers")
+	root.PersistentFlags().StringVar(&contextName, "context", "", "server profile to use instead of the current one")
+	root.RegisterFlagCompletionFunc("context", completeContexts)
+	root.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "output format,one of table,json,yaml,csv")
+	root.RegisterFlagCompletionFunc("output", completeOutput)
+	root.PersistentPreRunE = checkOutputFlag
+	root.AddCommand(
+		newListCmd(),
+		newGroupCmd(),
+		newGoCmd(),
+		newUserCmd(),
+		newRunCmd(),
+		newScriptCmd(),
+		newPushCmd(),
+		newPullCmd(),
+		newBroadcastCmd(),
+		newLoadCmd(),
+		newDeleteCmd(),
+		newDumpCmd(),
+		newTemplateCmd(),
+		newHostKeyCmd(),
+		newAuditCmd(),
+		newReplayCmd(),
+		newGrantCmd(),
+		newRequestAccessCmd(),
+		newContextCmd(),
+	)
+	return root
+}
+
+// exit codes of commands run on many hosts,any other error exits with 1
+const (
+	exitFailed      = 2 //some host failed
+	exitUnreachable = 3 //some host was unreachable
+)
+
+// exitError ends vsh with code,the summary already told why.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func main() {
+	err := newRootCmd().Execute()
+	if e, ok := err.(*exitError); ok {
+		os.Exit(e.code)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
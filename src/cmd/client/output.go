@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"meta"
+	"os"
+	"output"
+	"pb"
+	"sort"
+	"ssh"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// outputFlag is -o,empty keeps the output of each command for people.
+var outputFlag string
+
+func outputFormat() output.Format {
+	format, _ := output.ParseFormat(outputFlag)
+	return format
+}
+
+func checkOutputFlag(cmd *cobra.Command, args []string) error {
+	_, err := output.ParseFormat(outputFlag)
+	return err
+}
+
+func completeOutput(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	res := make([]string, 0, len(output.Formats))
+	for _, f := range output.Formats {
+		res = append(res, string(f))
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+func printRecords(header []string, records []output.Record) error {
+	return output.Write(os.Stdout, outputFormat(), header, records)
+}
+
+type nodeRecord struct {
+	Host  string `json:"host" yaml:"host"`
+	Tag   string `json:"tag" yaml:"tag"`
+	Group string `json:"group" yaml:"group"`
+}
+
+func (r *nodeRecord) Row() []string { return []string{r.Host, r.Tag, r.Group} }
+
+type groupRecord struct {
+	Group string `json:"group" yaml:"group"`
+	Nodes int32  `json:"nodes" yaml:"nodes"`
+}
+
+func (r *groupRecord) Row() []string { return []string{strconv.Itoa(int(r.Nodes)), r.Group} }
+
+type userRecord struct {
+	User  string   `json:"user" yaml:"user"`
+	Type  int32    `json:"type" yaml:"type"`
+	Roles []string `json:"roles" yaml:"roles"`
+}
+
+func (r *userRecord) Row() []string {
+	return []string{r.User, strconv.Itoa(int(r.Type)), strings.Join(r.Roles, ",")}
+}
+
+// responseRecord is the result of load,delete and hostkey on one node.
+type responseRecord struct {
+	Host    string `json:"host" yaml:"host"`
+	Group   string `json:"group" yaml:"group"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (r *responseRecord) Row() []string { return []string{r.Host, r.Group, r.Message} }
+
+type auditRecord struct {
+	Time    time.Time `json:"time" yaml:"time"`
+	User    string    `json:"user" yaml:"user"`
+	Addr    string    `json:"addr" yaml:"addr"`
+	Action  string    `json:"action" yaml:"action"`
+	Targets []string  `json:"targets" yaml:"targets"`
+	Result  string    `json:"result" yaml:"result"`
+	Detail  string    `json:"detail" yaml:"detail"`
+}
+
+func (r *auditRecord) Row() []string {
+	return []string{r.Time.Format("2006-01-02 15:04:05"), r.User, r.Addr, r.Action, strings.Join(r.Targets, ","), r.Result, r.Detail}
+}
+
+type grantRecord struct {
+	Id          int64      `json:"id" yaml:"id"`
+	User        string     `json:"user" yaml:"user"`
+	Groups      []string   `json:"groups" yaml:"groups"`
+	Tags        []string   `json:"tags" yaml:"tags"`
+	Addresses   []string   `json:"addresses" yaml:"addresses"`
+	Roles       []string   `json:"roles" yaml:"roles"`
+	Reason      string     `json:"reason" yaml:"reason"`
+	RequestedBy string     `json:"requested_by" yaml:"requested_by"`
+	ApprovedBy  string     `json:"approved_by,omitempty" yaml:"approved_by,omitempty"`
+	Duration    string     `json:"duration" yaml:"duration"`
+	ExpireAt    *time.Time `json:"expire_at,omitempty" yaml:"expire_at,omitempty"`
+}
+
+func (r *grantRecord) Row() []string {
+	status := fmt.Sprintf("pending %s,requested by %s", r.Duration, r.RequestedBy)
+	if r.ExpireAt != nil {
+		status = fmt.Sprintf("until %s,by %s", r.ExpireAt.Format("2006-01-02 15:04:05"), r.ApprovedBy)
+	}
+	return []string{strconv.FormatInt(r.Id, 10), r.User, strings.Join(r.Groups, ","), strings.Join(r.Tags, ","),
+		strings.Join(r.Addresses, ","), strings.Join(r.Roles, ","), status, r.Reason}
+}
+
+type contextRecord struct {
+	Name    string `json:"name" yaml:"name"`
+	Server  string `json:"server" yaml:"server"`
+	Current bool   `json:"current" yaml:"current"`
+}
+
+func (r *contextRecord) Row() []string {
+	mark := ""
+	if r.Current {
+		mark = "*"
+	}
+	return []string{mark, r.Name, r.Server}
+}
+
+// runRecord is the result of vsh run on one host.
+type runRecord struct {
+	Host       string  `json:"host" yaml:"host"`
+	Group      string  `json:"group" yaml:"group"`
+	Cmd        string  `json:"cmd" yaml:"cmd"`
+	Status     string  `json:"status" yaml:"status"`
+	ExitStatus int     `json:"exit_status" yaml:"exit_status"`
+	Signal     string  `json:"signal,omitempty" yaml:"signal,omitempty"`
+	Stdout     string  `json:"stdout" yaml:"stdout"`
+	Stderr     string  `json:"stderr" yaml:"stderr"`
+	Kind       string  `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Error      string  `json:"error,omitempty" yaml:"error,omitempty"`
+	Duration   float64 `json:"duration" yaml:"duration"` //seconds
+}
+
+func (r *runRecord) Row() []string {
+	return []string{r.Host, r.Group, r.Status, strconv.Itoa(r.ExitStatus), strconv.FormatFloat(r.Duration, 'f', 3, 64),
+		r.Stdout, r.Stderr, r.Error}
+}
+
+// summary keeps the first line of the output,for table rows.
+func (r *runRecord) summary() *runRecord {
+	s := *r
+	s.Stdout, s.Stderr = firstLine(r.Stdout), firstLine(r.Stderr)
+	return &s
+}
+
+var runHeader = []string{"host", "group", "status", "exit", "duration", "stdout", "stderr", "error"}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i]) + " ..."
+	}
+	return s
+}
+
+func newRunRecord(res *ssh.Result) *runRecord {
+	r := &runRecord{
+		Host:       res.Node.Ip,
+		Group:      res.Node.GroupName,
+		Cmd:        res.Cmd,
+		Status:     res.Status(),
+		ExitStatus: res.ExitStatus,
+		Signal:     res.Signal,
+		Stdout:     string(res.Stdout),
+		Stderr:     string(res.Stderr),
+		Kind:       string(res.Kind),
+		Duration:   res.Duration.Seconds(),
+	}
+	// the exit status already tells a command that ran and failed
+	if res.Err != nil && res.Kind != ssh.KindExit {
+		r.Error = res.Err.Error()
+	}
+	return r
+}
+
+func nodeRecords(nodes []*meta.Node) []output.Record {
+	records := make([]output.Record, 0, len(nodes))
+	for _, node := range nodes {
+		records = append(records, &nodeRecord{Host: node.Ip, Tag: node.Tag, Group: node.GroupName})
+	}
+	return records
+}
+
+func responseRecords(responses []*pb.Response) []output.Record {
+	sort.Slice(responses, func(i, j int) bool {
+		return strings.Compare(responses[i].Addr, responses[j].Addr) < 0
+	})
+	records := make([]output.Record, 0, len(responses))
+	for _, res := range responses {
+		records = append(records, &responseRecord{Host: res.Addr, Group: res.Group, Message: res.Msg})
+	}
+	return records
+}
+
+func grantRecords(grants []*pb.GrantEntry) []output.Record {
+	records := make([]output.Record, 0, len(grants))
+	for _, g := range grants {
+		r := &grantRecord{
+			Id:          g.Id,
+			User:        g.User,
+			Groups:      g.Groups,
+			Tags:        g.Tags,
+			Addresses:   g.Addresses,
+			Roles:       g.Roles,
+			Reason:      g.Reason,
+			RequestedBy: g.RequestedBy,
+			ApprovedBy:  g.ApprovedBy,
+			Duration:    (time.Duration(g.Duration) * time.Second).String(),
+		}
+		if len(g.ApprovedBy) > 0 {
+			expireAt := time.Unix(g.ExpireAt, 0)
+			r.ExpireAt = &expireAt
+		}
+		records = append(records, r)
+	}
+	return records
+}
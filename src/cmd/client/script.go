@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"ssh"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxCommandSize is the longest single argument linux takes,the wrapped
+	// script is one,sh -c runs it on the node.
+	maxCommandSize     = 128*1024 - 1
+	defaultInterpreter = "sh"
+)
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type scriptOptions struct {
+	runOptions
+	interpreter string
+	env         []string
+}
+
+// interpreterOf reads the interpreter from the #! line of script.
+func interpreterOf(script []byte) string {
+	if !bytes.HasPrefix(script, []byte("#!")) {
+		return defaultInterpreter
+	}
+	line := script[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if interpreter := strings.TrimSpace(string(line)); len(interpreter) > 0 {
+		return interpreter
+	}
+	return defaultInterpreter
+}
+
+// checkEnv accepts NAME=value pairs only.
+func checkEnv(env []string) error {
+	for _, kv := range env {
+		if name := strings.SplitN(kv, "=", 2)[0]; !strings.Contains(kv, "=") || !envNameRe.MatchString(name) {
+			return fmt.Errorf("invalid env %q,expect NAME=value", kv)
+		}
+	}
+	return nil
+}
+
+// scriptCommand wraps script into one shell command that writes it to a temp
+// file on the node,runs it with interpreter,args and env and removes it again.
+// The exit code of the script is kept. The script goes in a quoted here
+// document,so the audit log of the server shows it as written.
+func scriptCommand(script []byte, interpreter string, args, env []string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return wrapScript(script, "VSH_SCRIPT_"+hex.EncodeToString(b), interpreter, args, env)
+}
+
+// wrapScript is scriptCommand with marker ending the here document.
+func wrapScript(script []byte, marker, interpreter string, args, env []string) (string, error) {
+	if bytes.Contains(script, []byte(marker)) {
+		return "", fmt.Errorf("script contains %s", marker)
+	}
+	if !bytes.HasSuffix(script, []byte("\n")) {
+		script = append(script, '\n')
+	}
+	run := make([]string, 0, len(env)+len(args)+3)
+	if len(env) > 0 {
+		run = append(run, "env")
+		for _, kv := range env {
+			run = append(run, ssh.ShellQuote(kv))
+		}
+	}
+	run = append(run, interpreter, `"$f"`)
+	for _, arg := range args {
+		run = append(run, ssh.ShellQuote(arg))
+	}
+	var cmd bytes.Buffer
+	fmt.Fprintf(&cmd, "f=$(mktemp /tmp/vsh-script.XXXXXX) || exit 1\n")
+	fmt.Fprintf(&cmd, "cat > \"$f\" <<'%s'\n%s%s\n", marker, script, marker)
+	fmt.Fprintf(&cmd, "chmod 700 \"$f\"\n%s\nrc=$?\nrm -f \"$f\"\nexit $rc", strings.Join(run, " "))
+	return cmd.String(), nil
+}
+
+func runScript(o *scriptOptions, path string, args []string) error {
+	script, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = checkEnv(o.env); err != nil {
+		return err
+	}
+	interpreter := o.interpreter
+	if len(interpreter) == 0 {
+		interpreter = interpreterOf(script)
+	}
+	exeCmd, err := scriptCommand(script, interpreter, args, o.env)
+	if err != nil {
+		return err
+	}
+	if len(exeCmd) > maxCommandSize {
+		return fmt.Errorf("script %s with its arguments takes %d bytes,at most %d fit in a command", path, len(exeCmd), maxCommandSize)
+	}
+	detail := strings.Join(append([]string{interpreter, filepath.Base(path)}, args...), " ")
+	return execute(&o.runOptions, "script", exeCmd, detail)
+}
+
+func newScriptCmd() *cobra.Command {
+	o := &scriptOptions{}
+	cmd := &cobra.Command{
+		Use:   "script [flags] {file} [args...]",
+		Short: "upload a local script and run it on many nodes",
+		Long: `script copies a local script to a temp file on the selected nodes,runs it with
+its #! interpreter or --interpreter and removes it again. Put script arguments
+starting with - after --,such as vsh script -g web ./deploy.sh -- --force.
+The script travels inside the command,so it is limited to about 128KB. Nodes
+reached through the server check the whole command against the policies,an
+allow list policy refuses scripts.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScript(o, args[0], args[1:])
+		},
+	}
+	o.addFlags(cmd)
+	cmd.Flags().StringVarP(&o.interpreter, "interpreter", "i", "", "program running the script,the #! line or sh by default")
+	cmd.Flags().StringArrayVarP(&o.env, "env", "e", nil, "NAME=value set for the script,may repeat")
+	return cmd
+}
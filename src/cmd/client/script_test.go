@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpreterOf(t *testing.T) {
+	cases := []struct {
+		script      string
+		interpreter string
+	}{
+		{"#!/bin/bash\necho hi\n", "/bin/bash"},
+		{"#!/usr/bin/env python3\nprint(1)\n", "/usr/bin/env python3"},
+		{"#!  /bin/sh  ", "/bin/sh"},
+		{"#!\necho hi\n", defaultInterpreter},
+		{"echo hi\n#!/bin/bash\n", defaultInterpreter},
+		{"", defaultInterpreter},
+	}
+	for _, c := range cases {
+		if interpreter := interpreterOf([]byte(c.script)); interpreter != c.interpreter {
+			t.Errorf("%q expect %q,got %q", c.script, c.interpreter, interpreter)
+		}
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	cases := []struct {
+		env []string
+		ok  bool
+	}{
+		{nil, true},
+		{[]string{"A=1", "_B2=x y", "EMPTY="}, true},
+		{[]string{"A"}, false},
+		{[]string{"=1"}, false},
+		{[]string{"2A=1"}, false},
+		{[]string{"A-B=1"}, false},
+		{[]string{"A B=1"}, false},
+	}
+	for _, c := range cases {
+		if err := checkEnv(c.env); (err == nil) != c.ok {
+			t.Errorf("%q expect %v,got %v", c.env, c.ok, err)
+		}
+	}
+}
+
+// the wrapped script runs in a local sh like it does on a node.
+func TestWrapScript(t *testing.T) {
+	cases := []struct {
+		script string
+		args   []string
+		env    []string
+		output string
+		code   int
+	}{
+		{"echo hi", nil, nil, "hi\n", 0},
+		{"printf '%s|' \"$@\"", []string{"a b", "it's", "$HOME", "`id`", ""}, nil, "a b|it's|$HOME|`id`||", 0},
+		{"echo \"$A $B\"", nil, []string{"A=x y", "B='$(id)'"}, "x y '$(id)'\n", 0},
+		{"cat <<'EOF'\n$x\nEOF\nexit 3\n", nil, nil, "$x\n", 3},
+		{"echo ${1:-none}; exit 7", nil, nil, "none\n", 7},
+	}
+	for _, c := range cases {
+		cmd, err := wrapScript([]byte(c.script), "VSH_SCRIPT_test", "sh", c.args, c.env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command("sh", "-c", cmd).Output()
+		code := 0
+		if exit, ok := err.(*exec.ExitError); ok {
+			code = exit.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != c.output || code != c.code {
+			t.Errorf("%q expect %q exit %d,got %q exit %d", c.script, c.output, c.code, out, code)
+		}
+	}
+	if _, err := wrapScript([]byte("echo VSH_SCRIPT_test\n"), "VSH_SCRIPT_test", "sh", nil, nil); err == nil {
+		t.Error("script containing the marker was wrapped")
+	}
+}
+
+func TestScriptCommandCleanup(t *testing.T) {
+	cmd, err := scriptCommand([]byte("echo \"$0\""), "sh", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("sh", "-c", cmd).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(path, "/tmp/vsh-script.") {
+		t.Fatal("unexpected script path ", path)
+	}
+	if matches, _ := filepath.Glob(path); len(matches) > 0 {
+		t.Error("script left at ", path)
+	}
+}
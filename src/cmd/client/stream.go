@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"meta"
+	"os"
+	"os/signal"
+	"ssh"
+	"sync"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/net/context"
+)
+
+// hostColors cycle over the hosts of a streamed run.
+var hostColors = []string{"\x1b[32m", "\x1b[33m", "\x1b[34m", "\x1b[35m", "\x1b[36m",
+	"\x1b[92m", "\x1b[93m", "\x1b[94m", "\x1b[95m", "\x1b[96m"}
+
+const colorReset = "\x1b[0m"
+
+// streamPrinter prints the output of many hosts as it arrives,each line
+// prefixed by its host. Lines are written whole so hosts never mix in one.
+type streamPrinter struct {
+	mutex    sync.Mutex
+	prefixes map[string]string
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+func newStreamPrinter(nodes []*meta.Node, color bool) *streamPrinter {
+	// nodes of different groups may share an ip,their group tells them apart
+	ips := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		ips[node.Ip]++
+	}
+	names := make([]string, len(nodes))
+	width := 0
+	for i, node := range nodes {
+		names[i] = node.Ip
+		if ips[node.Ip] > 1 {
+			names[i] = node.GroupName + "/" + node.Ip
+		}
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+	p := &streamPrinter{prefixes: make(map[string]string, len(nodes)), stdout: os.Stdout, stderr: os.Stderr}
+	for i, node := range nodes {
+		prefix := fmt.Sprintf("%-*s", width, names[i])
+		if color {
+			prefix = hostColors[i%len(hostColors)] + prefix + colorReset
+		}
+		p.prefixes[streamKey(node)] = prefix + " | "
+	}
+	return p
+}
+
+// streamKey identifies the prefix of node by its group and ip.
+func streamKey(node *meta.Node) string {
+	return node.GroupName + "/" + node.Ip
+}
+
+// line is an ssh.LineFunc,stderr lines go to stderr.
+func (p *streamPrinter) line(node *meta.Node, stderr bool, line []byte) {
+	var buf bytes.Buffer
+	buf.WriteString(p.prefixes[streamKey(node)])
+	buf.Write(line)
+	buf.WriteByte('\n')
+	w := p.stdout
+	if stderr {
+		w = p.stderr
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	w.Write(buf.Bytes())
+}
+
+// result ends the output of a host with why it failed.
+func (p *streamPrinter) result(res *ssh.Result) {
+	if res.Err != nil {
+		p.line(res.Node, true, []byte(res.Err.Error()))
+	}
+}
+
+// useColor is true when stdout is a terminal and NO_COLOR is not set.
+func useColor() bool {
+	return len(os.Getenv("NO_COLOR")) == 0 && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// interruptContext returns a context canceled by the first ctrl-c,a second
+// one ends vsh at once. stop releases the signal again.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			fmt.Fprintln(os.Stderr, "canceling,ctrl-c again to quit at once")
+			signal.Stop(interrupt)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(interrupt)
+		cancel()
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"meta"
+	"testing"
+)
+
+func TestStreamPrefixes(t *testing.T) {
+	nodes := []*meta.Node{
+		{Ip: "10.0.0.1", GroupName: "web"},
+		{Ip: "10.0.0.1", GroupName: "db", Port: 2222},
+		{Ip: "10.0.0.10", GroupName: "web"},
+	}
+	p := newStreamPrinter(nodes, false)
+	var out bytes.Buffer
+	p.stdout = &out
+	for _, node := range nodes {
+		p.line(node, false, []byte("up"))
+	}
+	expect := "web/10.0.0.1 | up\ndb/10.0.0.1  | up\n10.0.0.10    | up\n"
+	if out.String() != expect {
+		t.Fatalf("expect\n%s\ngot\n%s", expect, out.String())
+	}
+	// without repeated ips the group is left out
+	p = newStreamPrinter(nodes[:1], false)
+	if prefix := p.prefixes[streamKey(nodes[0])]; prefix != "10.0.0.1 | " {
+		t.Fatalf("unexpected prefix %q", prefix)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"meta"
+	"output"
+	"sort"
+	"ssh"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// transferRecord is the result of push or pull on one host.
+type transferRecord struct {
+	Host     string  `json:"host" yaml:"host"`
+	Group    string  `json:"group" yaml:"group"`
+	Status   string  `json:"status" yaml:"status"`
+	Files    int     `json:"files" yaml:"files"`
+	Bytes    int64   `json:"bytes" yaml:"bytes"`
+	Error    string  `json:"error,omitempty" yaml:"error,omitempty"`
+	Duration float64 `json:"duration" yaml:"duration"` //seconds
+}
+
+func (r *transferRecord) Row() []string {
+	return []string{r.Host, r.Group, r.Status, strconv.Itoa(r.Files), strconv.FormatInt(r.Bytes, 10),
+		strconv.FormatFloat(r.Duration, 'f', 3, 64), r.Error}
+}
+
+// transfer runs push or pull on the selected nodes and prints one row per
+// host once all are done. Nodes the server keeps the credentials of can not
+// take part,they are reported as failed.
+func transfer(o *targetOptions, action, detail string, start func(e *ssh.Executor, nodes []*meta.Node) <-chan *ssh.Result) error {
+	cli, access, err := connect()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	if !permitted(access, "run") {
+		return fmt.Errorf("permission denied: %s", action)
+	}
+	nodes, err := o.selectNodes()
+	if err != nil {
+		return err
+	}
+	direct := make([]*meta.Node, 0, len(nodes))
+	results := make([]*ssh.Result, 0, len(nodes))
+	for _, node := range nodes {
+		if node.HasCredential() {
+			direct = append(direct, node)
+			continue
+		}
+		res := &ssh.Result{Node: node}
+		res.SetErr(errors.New("node credentials are kept by the server," + action + " needs them"))
+		results = append(results, res)
+	}
+	if len(direct) > 0 {
+		for res := range start(o.executor(), direct) {
+			results = append(results, res)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Node.GroupName != results[j].Node.GroupName {
+			return strings.Compare(results[i].Node.GroupName, results[j].Node.GroupName) < 0
+		}
+		return strings.Compare(results[i].Node.Ip, results[j].Node.Ip) < 0
+	})
+	failed := 0
+	records := make([]output.Record, 0, len(results))
+	for _, res := range results {
+		r := &transferRecord{Host: res.Node.Ip, Group: res.Node.GroupName, Status: res.Status(), Duration: res.Duration.Seconds()}
+		if res.Transfer != nil {
+			r.Files, r.Bytes = res.Transfer.Files, res.Transfer.Bytes
+		}
+		if res.Err != nil {
+			failed++
+			r.Error = res.Err.Error()
+		}
+		records = append(records, r)
+	}
+	if len(direct) > 0 {
+		targets := make([]string, 0, len(direct))
+		for _, node := range direct {
+			targets = append(targets, node.Ip)
+		}
+		report(cli, action, targets, fmt.Sprintf("%d ok,%d failed", len(results)-failed, failed), detail)
+	}
+	if err = printRecords([]string{"host", "group", "status", "files", "bytes", "duration", "error"}, records); err != nil {
+		return err
+	}
+	return summarize(results)
+}
+
+func newPushCmd() *cobra.Command {
+	o := &targetOptions{}
+	cmd := &cobra.Command{
+		Use:   "push [flags] {local} {remote}",
+		Short: "copy a local file or directory to many nodes",
+		Long: `push copies a local file or directory to remote on the selected nodes with
+scp,directories recursively. File modes and modification times are kept.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			local, remote := args[0], args[1]
+			return transfer(o, "push", local+" -> "+remote, func(e *ssh.Executor, nodes []*meta.Node) <-chan *ssh.Result {
+				return e.Push(nodes, local, remote)
+			})
+		},
+	}
+	o.addFlags(cmd)
+	return cmd
+}
+
+func newPullCmd() *cobra.Command {
+	o := &targetOptions{}
+	cmd := &cobra.Command{
+		Use:   "pull [flags] {remote} {localdir}",
+		Short: "copy a file or directory from many nodes",
+		Long: `pull copies remote from the selected nodes with scp,directories recursively.
+Each node gets its own subdirectory of localdir named after its ip,such as
+localdir/10.0.0.1/. File modes and modification times are kept.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, localDir := args[0], args[1]
+			return transfer(o, "pull", remote+" -> "+localDir, func(e *ssh.Executor, nodes []*meta.Node) <-chan *ssh.Result {
+				return e.Pull(nodes, remote, localDir)
+			})
+		},
+	}
+	o.addFlags(cmd)
+	return cmd
+}
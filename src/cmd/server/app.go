@@ -1,14 +1,20 @@
 package main
 
 import (
+	"cache"
+	"db"
+	"encode"
 	"flag"
 	log "logging"
+	"meta"
 	"os"
 	"os/signal"
 	"server"
+	"ssh"
 	"sync"
 	"syscall"
 	"time"
+	"utils"
 )
 
 const (
@@ -19,8 +25,45 @@ var (
 	port            = flag.Int("p", 5566, "server running port")
 	authorityConfig = flag.String("c", "config.json", "user privileges config")
 	dumpMinute      = flag.Int("d", defaultTimeOutMinute, "time interval for dump cluster")
+	tlsCert         = flag.String("tls_cert", "", "server certificate,enable tls")
+	tlsKey          = flag.String("tls_key", "", "server private key")
+	tlsClientCA     = flag.String("tls_client_ca", "", "ca to verify client certificates,enable mutual tls")
+	genCerts        = flag.String("gen_certs", "", "generate self-signed ca and certificates into this dir and exit")
+	certHosts       = flag.String("cert_hosts", "", "comma separated ip or dns names of server certificate")
+	certUsers       = flag.String("cert_users", "", "comma separated user names,such as root@10.0.0.1, to issue client certificates")
+	keyFile         = flag.String("key_file", "", "master key file encrypting the storage,default $"+encode.KeyFileEnv)
+	oldKeyFile      = flag.String("old_key_file", "", "previous master key file for rekey,empty means the built-in key")
+	cacheTTL        = flag.Duration("cache_ttl", server.DefaultCacheTTL, "how long a client may use its node cache before asking the server again")
+	proxyOnly       = flag.Bool("proxy_only", false, "never send node credentials to clients,relay their sessions instead")
+	recordDir       = flag.String("record_dir", "recordings", "record proxied sessions into this dir,empty disables")
+	genKey          = flag.String("gen_key", "", "generate a random master key into this file and exit")
 )
 
+// rekey re-encrypts the storage from the old master key to the configured one,
+// or to the built-in key when none is configured.
+func rekey() error {
+	oldCipher := encode.BuiltinCipher()
+	if len(*oldKeyFile) > 0 {
+		key, err := encode.ReadKeyFile(*oldKeyFile)
+		if err != nil {
+			return err
+		}
+		if oldCipher, err = encode.NewCipher(key); err != nil {
+			return err
+		}
+	}
+	if err := db.InitDBHandler(); err != nil {
+		return err
+	}
+	defer db.DBHandler.Close()
+	count, err := meta.Rekey(oldCipher, encode.DefaultCipher())
+	if err != nil {
+		return err
+	}
+	log.Info("rekey ", count, " records success")
+	return nil
+}
+
 func genTempateConfig(s *server.Server, stop chan struct{}) {
 	ticker := time.NewTicker(time.Second * 30)
 	defer ticker.Stop()
@@ -43,17 +86,58 @@ Loop:
 }
 func main() {
 	flag.Parse()
+	// agent sockets of nodes are the clients',never use the agent of the server
+	ssh.DisableAgent()
+	if len(*genCerts) > 0 {
+		if err := utils.GenerateCerts(*genCerts, cache.SplitList(*certHosts), cache.SplitList(*certUsers)); err != nil {
+			log.Fatal("generate certs:", err)
+		}
+		log.Info("generate certs in ", *genCerts, " success")
+		return
+	}
+	if len(*genKey) > 0 {
+		if err := encode.GenerateKeyFile(*genKey); err != nil {
+			log.Fatal("generate master key:", err)
+		}
+		log.Info("generate master key in ", *genKey, " success")
+		return
+	}
+	configured, err := encode.Init(*keyFile)
+	if err != nil {
+		log.Fatal("load master key:", err)
+	}
+	if !configured {
+		log.Warn("no master key configured,storage is encrypted with the built-in key")
+	}
+	if flag.Arg(0) == "rekey" {
+		if !configured {
+			log.Warn("rekey seals the records with the built-in key")
+		}
+		if err := rekey(); err != nil {
+			log.Fatal("rekey:", err)
+		}
+		return
+	}
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
-    done := make(chan struct{})
+	done := make(chan struct{})
 	wg := &sync.WaitGroup{}
 
 	wg.Add(1)
 	defer wg.Wait()
 
 	srv := server.NewServer(*port, *dumpMinute, *authorityConfig, wg)
+	if len(*tlsCert) > 0 {
+		if err := srv.EnableTLS(*tlsCert, *tlsKey, *tlsClientCA); err != nil {
+			log.Fatal("enable tls:", err)
+		}
+	}
+
+	srv.SetCacheTTL(*cacheTTL)
+	srv.SetProxyOnly(*proxyOnly)
+	srv.SetRecordDir(*recordDir)
 
-	go genTempateConfig(srv,done)
+	go genTempateConfig(srv, done)
 	go srv.Run()
 	defer log.Info("....vsh_server exit...")
 	for {
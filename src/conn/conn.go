@@ -8,15 +8,44 @@ import (
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 type Conn struct {
 	connection *grpc.ClientConn
 }
 
-func NewConn(addr string, port int) (*Conn, error) {
+// TLSOption holds the pem files used to talk to a vsh_server over TLS.
+// Cert and Key are only needed when the server verifies client certificates.
+type TLSOption struct {
+	CA         string
+	Cert       string
+	Key        string
+	ServerName string
+}
+
+func (o *TLSOption) dialOption() (grpc.DialOption, error) {
+	if o == nil || (len(o.CA) == 0 && len(o.Cert) == 0) {
+		return grpc.WithInsecure(), nil
+	}
+	conf, pool, err := utils.NewTLSConfig(o.CA, o.Cert, o.Key)
+	if err != nil {
+		return nil, err
+	}
+	conf.RootCAs = pool
+	conf.ServerName = o.ServerName
+	return grpc.WithTransportCredentials(credentials.NewTLS(conf)), nil
+}
+
+func NewConn(addr string, port int, tlsOption *TLSOption) (*Conn, error) {
 	addrInfo := fmt.Sprintf("%s:%d", addr, port)
-	conn, err := grpc.Dial(addrInfo, grpc.WithInsecure())
+	opt, err := tlsOption.dialOption()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(addrInfo, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -25,6 +54,12 @@ func NewConn(addr string, port int) (*Conn, error) {
 	}, nil
 
 }
+
+// IsUnavailable reports whether err means the server could not be reached,
+// rather than the server rejecting the call.
+func IsUnavailable(err error) bool {
+	return err != nil && status.Code(err) == codes.Unavailable
+}
 func (a *Conn) Close() {
 	a.connection.Close()
 }
@@ -137,3 +172,71 @@ func (a *Conn) NewBasicSession() (*pb.BasicResponse, error) {
 	}
 	return resp, nil
 }
+func (a *Conn) NewHostKeySession(hosts []string) (*pb.HostKeyResponse, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewServerNodeServiceClient(a.connection)
+	req := &pb.HostKeyRequest{
+		Hosts:    hosts,
+		Username: strings.ToLower(username),
+	}
+	return c.HostKey(context.Background(), req)
+}
+
+// NewReportSession records an action the client ran itself in the server audit log.
+func (a *Conn) NewReportSession(action string, targets []string, result, detail string) (*pb.ReportResponse, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewServerNodeServiceClient(a.connection)
+	req := &pb.ReportRequest{
+		Username: strings.ToLower(username),
+		Action:   action,
+		Targets:  targets,
+		Result:   result,
+		Detail:   detail,
+	}
+	return c.Report(context.Background(), req)
+}
+func (a *Conn) NewAuditSession(req *pb.AuditRequest) (*pb.AuditResponse, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewServerNodeServiceClient(a.connection)
+	req.Username = strings.ToLower(username)
+	return c.Audit(context.Background(), req)
+}
+
+// NewExecSession runs req.Cmd on req.Hosts through the server,which checks the
+// command policies. Canceling ctx stops the commands still running.
+func (a *Conn) NewExecSession(ctx context.Context, req *pb.ExecRequest) (pb.ServerNodeService_ExecClient, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewServerNodeServiceClient(a.connection)
+	req.Username = strings.ToLower(username)
+	return c.Exec(ctx, req)
+}
+func (a *Conn) NewGrantSession(req *pb.GrantRequest) (*pb.GrantResponse, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewServerNodeServiceClient(a.connection)
+	req.Username = strings.ToLower(username)
+	return c.Grant(context.Background(), req)
+}
+func (a *Conn) NewRequestAccessSession(req *pb.GrantRequest) (*pb.GrantResponse, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	c := pb.NewServerNodeServiceClient(a.connection)
+	req.Username = strings.ToLower(username)
+	return c.RequestAccess(context.Background(), req)
+}
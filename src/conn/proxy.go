@@ -0,0 +1,160 @@
+package conn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"pb"
+	"strings"
+	"sync"
+	"utils"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/context"
+)
+
+// ProxySession is a terminal session to a node relayed by vsh_server, which
+// dials the node with the stored credentials. It satisfies ssh.Session.
+type ProxySession struct {
+	host     string
+	username string
+	stream   pb.ServerNodeService_ProxyClient
+	cancel   context.CancelFunc
+	sendLock sync.Mutex
+
+	term          string
+	height, width int
+
+	stdoutReader, stderrReader *io.PipeReader
+	stdoutWriter, stderrWriter *io.PipeWriter
+	done                       chan struct{}
+	err                        error
+}
+
+func (a *Conn) NewProxySession(host string) (*ProxySession, error) {
+	username, err := utils.GetUserName()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewServerNodeServiceClient(a.connection).Proxy(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	p := &ProxySession{
+		host:     host,
+		username: strings.ToLower(username),
+		stream:   stream,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	p.stdoutReader, p.stdoutWriter = io.Pipe()
+	p.stderrReader, p.stderrWriter = io.Pipe()
+	return p, nil
+}
+
+func (p *ProxySession) send(req *pb.ProxyRequest) error {
+	p.sendLock.Lock()
+	defer p.sendLock.Unlock()
+	return p.stream.Send(req)
+}
+
+// RequestPty keeps the pty settings until Shell opens the session on the server.
+func (p *ProxySession) RequestPty(term string, h, w int, termmodes ssh.TerminalModes) error {
+	p.term, p.height, p.width = term, h, w
+	return nil
+}
+func (p *ProxySession) WindowChange(h, w int) error {
+	return p.send(&pb.ProxyRequest{
+		Height: int32(h),
+		Width:  int32(w),
+	})
+}
+func (p *ProxySession) StdinPipe() (io.WriteCloser, error) {
+	return &proxyStdin{p}, nil
+}
+func (p *ProxySession) StdoutPipe() (io.Reader, error) {
+	return p.stdoutReader, nil
+}
+func (p *ProxySession) StderrPipe() (io.Reader, error) {
+	return p.stderrReader, nil
+}
+func (p *ProxySession) Shell() error {
+	err := p.send(&pb.ProxyRequest{
+		Host:     p.host,
+		Username: p.username,
+		Term:     p.term,
+		Height:   int32(p.height),
+		Width:    int32(p.width),
+	})
+	if err != nil {
+		return err
+	}
+	go p.receive()
+	return nil
+}
+
+func (p *ProxySession) receive() {
+	defer close(p.done)
+	for {
+		resp, err := p.stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("proxy closed by server")
+			}
+			p.finish(err)
+			return
+		}
+		if len(resp.Stdout) > 0 {
+			p.stdoutWriter.Write(resp.Stdout)
+		}
+		if len(resp.Stderr) > 0 {
+			p.stderrWriter.Write(resp.Stderr)
+		}
+		if resp.Exit {
+			switch {
+			case len(resp.Msg) > 0:
+				p.finish(errors.New(resp.Msg))
+			case resp.ExitStatus != 0:
+				p.finish(fmt.Errorf("Process exited with status %d", resp.ExitStatus))
+			default:
+				p.finish(nil)
+			}
+			return
+		}
+	}
+}
+func (p *ProxySession) finish(err error) {
+	p.err = err
+	p.stdoutWriter.Close()
+	p.stderrWriter.Close()
+}
+
+func (p *ProxySession) Wait() error {
+	<-p.done
+	return p.err
+}
+func (p *ProxySession) Close() error {
+	p.sendLock.Lock()
+	err := p.stream.CloseSend()
+	p.sendLock.Unlock()
+	p.cancel()
+	return err
+}
+
+type proxyStdin struct {
+	p *ProxySession
+}
+
+func (in *proxyStdin) Write(b []byte) (int, error) {
+	data := make([]byte, len(b))
+	copy(data, b)
+	if err := in.p.send(&pb.ProxyRequest{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+func (in *proxyStdin) Close() error {
+	return in.p.Close()
+}
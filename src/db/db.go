@@ -8,8 +8,12 @@ import (
 )
 
 const (
-	DefaultClusterNodeBucket  = "CLUSTER_NODE"
-	DefaultClusterGroupBucket = "CLUSTER_GROUP"
+	DefaultClusterNodeBucket    = "CLUSTER_NODE"
+	DefaultClusterGroupBucket   = "CLUSTER_GROUP"
+	DefaultClusterHostKeyBucket = "CLUSTER_HOSTKEY"
+	DefaultClusterAuditBucket   = "CLUSTER_AUDIT"
+	DefaultClusterGrantBucket   = "CLUSTER_GRANT"
+	DefaultClusterSecretBucket  = "CLUSTER_SECRET"
 )
 const (
 	DefaultStorageFile = "./vsh.db"
@@ -40,6 +44,18 @@ func InitDBHandler() error {
 		if _, err = tx.CreateBucketIfNotExists([]byte(DefaultClusterGroupBucket)); err != nil {
 			return err
 		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(DefaultClusterHostKeyBucket)); err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(DefaultClusterAuditBucket)); err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(DefaultClusterGrantBucket)); err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(DefaultClusterSecretBucket)); err != nil {
+			return err
+		}
 		DBHandler = db
 	}
 	return nil
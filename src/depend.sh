@@ -6,3 +6,8 @@ govendor fetch google.golang.org/grpc
 govendor fetch golang.org/x/crypto/ssh
 govendor fetch golang.org/x/crypto/ssh/terminal
 govendor fetch github.com/boltdb/bolt
+govendor fetch golang.org/x/crypto/ssh/agent
+govendor fetch golang.org/x/crypto/pbkdf2
+govendor fetch github.com/spf13/cobra@v1.2.1
+govendor fetch github.com/spf13/pflag@v1.0.5
+govendor fetch gopkg.in/yaml.v2@v2.4.0
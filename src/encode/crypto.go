@@ -3,24 +3,125 @@ package encode
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	KeyEnv        = "VSH_MASTER_KEY"        //hex or base64 encoded 32 bytes key
+	KeyFileEnv    = "VSH_MASTER_KEY_FILE"   //file holding the key
+	PassphraseEnv = "VSH_MASTER_PASSPHRASE" //passphrase the key is derived from
+	KeySize       = 32
+)
+
+const (
+	sealVersion   = byte(1)
+	kdfIterations = 100000
+	kdfSalt       = "ops-ssh/vsh master key"
 )
 
+// records written before the master key existed were encrypted with this
+// built-in key in CFB mode; only the built-in cipher reads them,so a server
+// without a master key keeps its data and rekey can move them to a new key.
+// A configured key never reads them.
 var commonIV = []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
 
 var commonKey = "abcdefg@2019$0123456789!@#$%^*&("
 
-func Encoding(b []byte) ([]byte, error) {
-	c, err := aes.NewCipher([]byte(commonKey))
+var (
+	InvalidKeyErr   = errors.New("master key must be 32 bytes")
+	AuthenticateErr = errors.New("decrypt failed,wrong master key or corrupted data")
+	defaultCipher   = BuiltinCipher()
+	configured      bool
+)
+
+// Cipher seals records with AES-256-GCM and a random nonce per record.
+type Cipher struct {
+	key    []byte
+	aead   cipher.AEAD
+	legacy bool //also reads the CFB records of the built-in key
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, InvalidKeyErr
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
 	if err != nil {
 		return nil, err
 	}
-	// encoding string
-	cfb := cipher.NewCFBEncrypter(c, commonIV)
-	ciphertext := make([]byte, len(b))
-	cfb.XORKeyStream(ciphertext, b)
-	return ciphertext, nil
+	return &Cipher{
+		key:  append([]byte{}, key...),
+		aead: aead,
+	}, nil
 }
-func Decoding(b []byte) ([]byte, error) {
+
+// Key returns a copy of the raw key.
+func (c *Cipher) Key() []byte {
+	return append([]byte{}, c.key...)
+}
+
+// SubKey derives a key bound to info,such as a user name,from the master key.
+func (c *Cipher) SubKey(info string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(info))
+	return mac.Sum(nil)
+}
+
+// Encrypt returns version|nonce|ciphertext.
+func (c *Cipher) Encrypt(b []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 1+len(nonce)+len(b)+c.aead.Overhead())
+	out = append(out, sealVersion)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, b, nil), nil
+}
+
+// Decrypt opens a record sealed by Encrypt,anything else is rejected.
+func (c *Cipher) Decrypt(b []byte) ([]byte, error) {
+	origin, err := c.open(b)
+	if err != nil && c.legacy {
+		// a legacy record may start with the version byte too
+		return legacyDecoding(b)
+	}
+	return origin, err
+}
+
+func (c *Cipher) open(b []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(b) < 1+nonceSize || b[0] != sealVersion {
+		return nil, AuthenticateErr
+	}
+	origin, err := c.aead.Open(nil, b[1:1+nonceSize], b[1+nonceSize:], nil)
+	if err != nil {
+		return nil, AuthenticateErr
+	}
+	return origin, nil
+}
+
+// legacyDecoding reads a CFB record of the built-in key. CFB does not
+// authenticate,the records were json so anything else is taken as sealed
+// with another key.
+func legacyDecoding(b []byte) ([]byte, error) {
 	c, err := aes.NewCipher([]byte(commonKey))
 	if err != nil {
 		return nil, err
@@ -28,5 +129,105 @@ func Decoding(b []byte) ([]byte, error) {
 	cfbdec := cipher.NewCFBDecrypter(c, commonIV)
 	originData := make([]byte, len(b))
 	cfbdec.XORKeyStream(originData, b)
+	if !json.Valid(originData) {
+		return nil, AuthenticateErr
+	}
 	return originData, nil
 }
+
+// ParseKey accepts a key as 64 hex chars, base64 or 32 raw bytes.
+func ParseKey(b []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(b))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if len(b) == KeySize {
+		return b, nil
+	}
+	return nil, InvalidKeyErr
+}
+
+func DeriveKey(passphrase string) []byte {
+	return pbkdf2.Key([]byte(passphrase), []byte(kdfSalt), kdfIterations, KeySize, sha256.New)
+}
+
+func ReadKeyFile(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return key, nil
+}
+
+// GenerateKeyFile writes a random hex encoded key to path.
+func GenerateKeyFile(path string) error {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600)
+}
+
+// LoadKey finds the master key in keyFile, then $VSH_MASTER_KEY_FILE,
+// $VSH_MASTER_KEY and $VSH_MASTER_PASSPHRASE. It returns nil when none is set.
+func LoadKey(keyFile string) ([]byte, error) {
+	if len(keyFile) == 0 {
+		keyFile = os.Getenv(KeyFileEnv)
+	}
+	if len(keyFile) > 0 {
+		return ReadKeyFile(keyFile)
+	}
+	if text := os.Getenv(KeyEnv); len(text) > 0 {
+		return ParseKey([]byte(text))
+	}
+	if passphrase := os.Getenv(PassphraseEnv); len(passphrase) > 0 {
+		return DeriveKey(passphrase), nil
+	}
+	return nil, nil
+}
+
+// Init sets the key used by Encoding and Decoding. It reports false when no
+// key is configured and the built-in key stays in use.
+func Init(keyFile string) (bool, error) {
+	key, err := LoadKey(keyFile)
+	if err != nil || key == nil {
+		return false, err
+	}
+	c, err := NewCipher(key)
+	if err != nil {
+		return false, err
+	}
+	defaultCipher, configured = c, true
+	return true, nil
+}
+
+// Configured reports whether Init found a master key.
+func Configured() bool {
+	return configured
+}
+
+func DefaultCipher() *Cipher {
+	return defaultCipher
+}
+
+// BuiltinCipher returns the built-in key,it also reads the legacy CFB records.
+// It is the default cipher until Init finds a configured key.
+func BuiltinCipher() *Cipher {
+	c, _ := NewCipher([]byte(commonKey))
+	c.legacy = true
+	return c
+}
+
+func Encoding(b []byte) ([]byte, error) {
+	return defaultCipher.Encrypt(b)
+}
+func Decoding(b []byte) ([]byte, error) {
+	return defaultCipher.Decrypt(b)
+}
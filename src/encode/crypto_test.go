@@ -0,0 +1,68 @@
+package encode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	c, err := NewCipher(DeriveKey("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := []byte(`{"ip":"127.0.0.1","password":"test"}`)
+	a, err := c.Encrypt(origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := c.Encrypt(origin)
+	if bytes.Equal(a, b) {
+		t.Fatal("same nonce used twice")
+	}
+	rb, err := c.Decrypt(a)
+	if err != nil || !bytes.Equal(rb, origin) {
+		t.Fatal("decrypt:", err, string(rb))
+	}
+	other, _ := NewCipher(DeriveKey("other"))
+	if _, err = other.Decrypt(a); err != AuthenticateErr {
+		t.Fatal("expect authenticate error, got ", err)
+	}
+}
+
+func legacyEncoding(origin []byte) []byte {
+	block, _ := aes.NewCipher([]byte(commonKey))
+	legacy := make([]byte, len(origin))
+	cipher.NewCFBEncrypter(block, commonIV).XORKeyStream(legacy, origin)
+	return legacy
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	origin := []byte(`{"groups":{"image":1}}`)
+	legacy := legacyEncoding(origin)
+	c, _ := NewCipher(DeriveKey("secret"))
+	if _, err := c.Decrypt(legacy); err != AuthenticateErr {
+		t.Fatal("expect legacy record rejected, got ", err)
+	}
+	// a server without a master key keeps reading what it stored before
+	for _, builtin := range []*Cipher{DefaultCipher(), BuiltinCipher()} {
+		rb, err := builtin.Decrypt(legacy)
+		if err != nil || !bytes.Equal(rb, origin) {
+			t.Fatal("decrypt legacy:", err, string(rb))
+		}
+	}
+	sealed, _ := c.Encrypt(origin)
+	if _, err := BuiltinCipher().Decrypt(sealed); err != AuthenticateErr {
+		t.Fatal("expect record of another key rejected, got ", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if _, err := ParseKey([]byte("00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseKey([]byte("short")); err != InvalidKeyErr {
+		t.Fatal("expect invalid key error, got ", err)
+	}
+}
@@ -0,0 +1,111 @@
+package meta
+
+import (
+	"db"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	DefaultAuditLimit = 100
+)
+
+// AuditEntry is one action of a user. Entries are only ever appended,keyed by
+// the bucket sequence so they stay in the order they happened.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Addr    string    `json:"addr,omitempty"`
+	Action  string    `json:"action"`
+	Targets []string  `json:"targets,omitempty"`
+	Result  string    `json:"result"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+type AuditFilter struct {
+	User  string
+	Node  string
+	Since time.Time //zero means no bound
+	Until time.Time
+	Limit int //newest entries kept
+}
+
+func (f *AuditFilter) match(e *AuditEntry) bool {
+	if len(f.User) > 0 && f.User != e.User {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	if len(f.Node) == 0 {
+		return true
+	}
+	for _, target := range e.Targets {
+		if target == f.Node {
+			return true
+		}
+	}
+	return false
+}
+
+func AppendAudit(e *AuditEntry) error {
+	if db.DBHandler == nil {
+		return db.HandleIsNilErr
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return db.DBHandler.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(db.DefaultClusterAuditBucket))
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return bucket.Put(key, b)
+	})
+}
+
+// QueryAudit walks the log from the newest entry and returns at most
+// f.Limit matching entries,oldest first.
+func QueryAudit(f *AuditFilter) ([]*AuditEntry, error) {
+	if db.DBHandler == nil {
+		return nil, db.HandleIsNilErr
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	entries := make([]*AuditEntry, 0)
+	err := db.DBHandler.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(db.DefaultClusterAuditBucket)).Cursor()
+		for k, v := cursor.Last(); k != nil && len(entries) < limit; k, v = cursor.Prev() {
+			e := &AuditEntry{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return err
+			}
+			if !f.Since.IsZero() && e.Time.Before(f.Since) {
+				break
+			}
+			if f.match(e) {
+				entries = append(entries, e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
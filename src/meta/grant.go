@@ -0,0 +1,139 @@
+package meta
+
+import (
+	"db"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	GrantNotExistErr = errors.New("grant not exists")
+)
+
+// Grant gives a user extra roles and nodes for a limited time. A requested
+// grant only counts once a super user approved it,its clock starts then.
+type Grant struct {
+	Id          uint64        `json:"id"`
+	User        string        `json:"user"`
+	Groups      []string      `json:"groups,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Addresses   []string      `json:"addresses,omitempty"`
+	Roles       []string      `json:"roles,omitempty"`
+	Reason      string        `json:"reason,omitempty"`
+	RequestedBy string        `json:"requested_by"`
+	ApprovedBy  string        `json:"approved_by,omitempty"`
+	Created     time.Time     `json:"created"`
+	Duration    time.Duration `json:"duration"`
+	ExpireAt    time.Time     `json:"expire_at"` //zero until approved
+}
+
+func (g *Grant) Approved() bool {
+	return len(g.ApprovedBy) > 0
+}
+
+// Active reports whether the grant is approved and not expired at now.
+func (g *Grant) Active(now time.Time) bool {
+	return g.Approved() && now.Before(g.ExpireAt)
+}
+
+// Approve starts the grant at now.
+func (g *Grant) Approve(by string, now time.Time) {
+	g.ApprovedBy = by
+	g.ExpireAt = now.Add(g.Duration)
+}
+
+func grantKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+// AddGrant stores g under a new id.
+func AddGrant(g *Grant) error {
+	if db.DBHandler == nil {
+		return db.HandleIsNilErr
+	}
+	return db.DBHandler.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(db.DefaultClusterGrantBucket))
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		g.Id = seq
+		b, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(grantKey(seq), b)
+	})
+}
+
+func UpdateGrant(g *Grant) error {
+	if db.DBHandler == nil {
+		return db.HandleIsNilErr
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	return db.DBHandler.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(db.DefaultClusterGrantBucket))
+		if bucket.Get(grantKey(g.Id)) == nil {
+			return GrantNotExistErr
+		}
+		return bucket.Put(grantKey(g.Id), b)
+	})
+}
+
+func FetchGrant(id uint64) (*Grant, error) {
+	if db.DBHandler == nil {
+		return nil, db.HandleIsNilErr
+	}
+	g := &Grant{}
+	err := db.DBHandler.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(db.DefaultClusterGrantBucket)).Get(grantKey(id))
+		if b == nil {
+			return GrantNotExistErr
+		}
+		return json.Unmarshal(b, g)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// FetchGrants returns every stored grant,oldest first.
+func FetchGrants() ([]*Grant, error) {
+	if db.DBHandler == nil {
+		return nil, db.HandleIsNilErr
+	}
+	grants := make([]*Grant, 0)
+	err := db.DBHandler.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(db.DefaultClusterGrantBucket)).ForEach(func(k, v []byte) error {
+			g := &Grant{}
+			if err := json.Unmarshal(v, g); err != nil {
+				return err
+			}
+			grants = append(grants, g)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func DeleteGrant(id uint64) error {
+	if db.DBHandler == nil {
+		return db.HandleIsNilErr
+	}
+	return db.DBHandler.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(db.DefaultClusterGrantBucket)).Delete(grantKey(id))
+	})
+}
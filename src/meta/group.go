@@ -16,10 +16,13 @@ type Group struct {
 	Addrs     map[string]uint8    `json:"hosts"`
 }
 
-func FetchGroup() *Group {
+// FetchGroup returns nil and no error before any node is loaded. A group
+// that fails to decrypt is an error,writing a new one over it would lose
+// every node of it.
+func FetchGroup() (*Group, error) {
 	var group *Group
 	if db.DBHandler == nil {
-		return nil
+		return nil, db.HandleIsNilErr
 	}
 	err := db.DBHandler.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(db.DefaultClusterGroupBucket)).Get([]byte(db.DefaultGroupKey))
@@ -38,11 +41,10 @@ func FetchGroup() *Group {
 		return nil
 	})
 	if err != nil {
-		log.Error(err)
-		group = nil
-		return nil
+		log.Error("fetch group:", err)
+		return nil, err
 	}
-	return group
+	return group, nil
 
 }
 func (g *Group) Bytes() []byte {
@@ -0,0 +1,64 @@
+package meta
+
+import (
+	"db"
+	"encode"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+// tempDB opens vsh.db in a temporary directory,the returned func closes and
+// removes it.
+func tempDB(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.InitDBHandler(); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		db.DBHandler.Close()
+		db.DBHandler = nil
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+}
+
+// a group sealed with another key must not read as no group,Load would write
+// an empty one over it.
+func TestFetchGroupWrongKey(t *testing.T) {
+	defer tempDB(t)()
+	if group, err := FetchGroup(); group != nil || err != nil {
+		t.Fatal("expect no group before the first load,got ", group, err)
+	}
+	group := &Group{
+		GroupMeta: map[string]uint8{"web": 1},
+		Ref:       map[string][]string{"web": {"192.0.2.1"}},
+		Addrs:     map[string]uint8{"192.0.2.1": 1},
+	}
+	if err := group.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if fetched, err := FetchGroup(); err != nil || len(fetched.Ref["web"]) != 1 {
+		t.Fatal("fetch group:", fetched, err)
+	}
+	other, _ := encode.NewCipher(encode.DeriveKey("other"))
+	sealed, _ := other.Encrypt([]byte(`{"groups":{"db":1}}`))
+	err := db.DBHandler.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(db.DefaultClusterGroupBucket)).Put([]byte(db.DefaultGroupKey), sealed)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched, err := FetchGroup(); err == nil {
+		t.Fatal("expect a decrypt error,got ", fetched)
+	}
+}
@@ -0,0 +1,46 @@
+package meta
+
+import (
+	"db"
+	log "logging"
+
+	"github.com/boltdb/bolt"
+)
+
+// FetchHostKey returns the trusted host key of ip in authorized_keys format,
+// or an empty string when no key has been recorded yet.
+func FetchHostKey(ip string) string {
+	var hostKey string
+	if db.DBHandler == nil {
+		return hostKey
+	}
+	err := db.DBHandler.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(db.DefaultClusterHostKeyBucket)).Get([]byte(ip))
+		if b != nil {
+			hostKey = string(b)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("fetchHostKey ", ip, ":", err)
+	}
+	return hostKey
+}
+
+func UpdateHostKey(ip string, hostKey string) error {
+	if db.DBHandler == nil {
+		return db.HandleIsNilErr
+	}
+	return db.DBHandler.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(db.DefaultClusterHostKeyBucket)).Put([]byte(ip), []byte(hostKey))
+	})
+}
+
+func DeleteHostKey(ip string) error {
+	if db.DBHandler == nil {
+		return db.HandleIsNilErr
+	}
+	return db.DBHandler.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(db.DefaultClusterHostKeyBucket)).Delete([]byte(ip))
+	})
+}
@@ -0,0 +1,45 @@
+package meta
+
+import (
+	"db"
+	"os"
+	"testing"
+)
+
+func TestHostKeyStore(t *testing.T) {
+	if _, err := os.Stat(db.DefaultStorageFile); os.IsNotExist(err) {
+		defer os.Remove(db.DefaultStorageFile)
+	}
+	if err := db.InitDBHandler(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		db.DBHandler.Close()
+		db.DBHandler = nil
+	}()
+	const ip = "192.0.2.1"
+	defer DeleteHostKey(ip)
+	if key := FetchHostKey(ip); len(key) > 0 {
+		t.Fatal("unexpected key before first use ", key)
+	}
+	first, rotated := "ssh-ed25519 AAAAfirst", "ssh-ed25519 AAAArotated"
+	if err := UpdateHostKey(ip, first); err != nil {
+		t.Fatal(err)
+	}
+	if key := FetchHostKey(ip); key != first {
+		t.Fatal("expect the key of first use,got ", key)
+	}
+	// vsh hostkey accepts a rotated key over the recorded one
+	if err := UpdateHostKey(ip, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if key := FetchHostKey(ip); key != rotated {
+		t.Fatal("expect the rotated key,got ", key)
+	}
+	if err := DeleteHostKey(ip); err != nil {
+		t.Fatal(err)
+	}
+	if key := FetchHostKey(ip); len(key) > 0 {
+		t.Fatal("key left after delete ", key)
+	}
+}
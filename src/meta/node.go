@@ -13,12 +13,17 @@ import (
 )
 
 type Node struct {
-	Ip        string `json:"ip"`
-	Port      int    `json:"port"`
-	UserName  string `json:"user"`
-	Password  string `json:"password"`
-	Tag       string `json:"tag,omitempty"`
-	GroupName string `json:"group,omitempty"`
+	Ip          string `json:"ip"`
+	Port        int    `json:"port"`
+	UserName    string `json:"user"`
+	Password    string `json:"password"`
+	KeyPath     string `json:"key_path,omitempty"`     //private key file,read into Key when loading
+	Key         string `json:"key,omitempty"`          //inline private key in pem
+	Passphrase  string `json:"passphrase,omitempty"`   //passphrase of private key
+	AgentSocket string `json:"agent_socket,omitempty"` //ssh-agent socket,such as $SSH_AUTH_SOCK
+	HostKey     string `json:"host_key,omitempty"`     //trusted host key,filled from CLUSTER_HOSTKEY on query
+	Tag         string `json:"tag,omitempty"`
+	GroupName   string `json:"group,omitempty"`
 }
 
 func FetchNode(ip string) *Node {
@@ -63,6 +68,15 @@ func (n *Node) Compare(nd *Node) bool {
 	if strings.Compare(n.Password, nd.Password) != 0 {
 		return false
 	}
+	if strings.Compare(n.Key, nd.Key) != 0 {
+		return false
+	}
+	if strings.Compare(n.Passphrase, nd.Passphrase) != 0 {
+		return false
+	}
+	if strings.Compare(n.AgentSocket, nd.AgentSocket) != 0 {
+		return false
+	}
 	if strings.Compare(n.GroupName, nd.GroupName) != 0 {
 		return false
 	}
@@ -72,6 +86,18 @@ func (n *Node) Compare(nd *Node) bool {
 
 	return true
 }
+
+// HasCredential reports whether node can be dialed directly,nodes queried
+// from a proxy only server carry no credentials.
+func (n *Node) HasCredential() bool {
+	return len(n.Password) > 0 || len(n.Key) > 0 || len(n.KeyPath) > 0 || len(n.AgentSocket) > 0
+}
+
+// AgentOnly reports whether node has no credential but an ssh-agent socket,
+// the agent is the one of the client and of no use on the server.
+func (n *Node) AgentOnly() bool {
+	return len(n.AgentSocket) > 0 && len(n.Password) == 0 && len(n.Key) == 0 && len(n.KeyPath) == 0
+}
 func (node *Node) Bytes() []byte {
 	b, err := json.Marshal(node)
 	if err != nil {
@@ -105,7 +131,7 @@ func (node *Node) Delete() error {
 		bucket := tx.Bucket([]byte(db.DefaultClusterNodeBucket))
 		b := bucket.Get([]byte(node.Ip))
 		if b == nil {
-			return errors.New(fmt.Sprintf("node ", node.Ip, " not exists"))
+			return errors.New(fmt.Sprintf("node %s not exists", node.Ip))
 		}
 		if err := bucket.Delete([]byte(node.Ip)); err != nil {
 			log.Error(err)
@@ -0,0 +1,51 @@
+package meta
+
+import (
+	"db"
+	"encode"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+// Rekey decrypts every node and group record with oldCipher and writes it back
+// sealed with newCipher in a single transaction,so a failure leaves the store
+// untouched. It returns the number of records rewritten.
+func Rekey(oldCipher, newCipher *encode.Cipher) (int, error) {
+	if db.DBHandler == nil {
+		return 0, db.HandleIsNilErr
+	}
+	count := 0
+	err := db.DBHandler.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{db.DefaultClusterNodeBucket, db.DefaultClusterGroupBucket} {
+			bucket := tx.Bucket([]byte(name))
+			records := make(map[string][]byte)
+			err := bucket.ForEach(func(k, v []byte) error {
+				origin, err := oldCipher.Decrypt(v)
+				if err != nil {
+					return fmt.Errorf("%s/%s: %v", name, string(k), err)
+				}
+				sealed, err := newCipher.Encrypt(origin)
+				if err != nil {
+					return err
+				}
+				records[string(k)] = sealed
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for k, v := range records {
+				if err := bucket.Put([]byte(k), v); err != nil {
+					return err
+				}
+			}
+			count += len(records)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,37 @@
+package meta
+
+import (
+	"crypto/rand"
+	"db"
+	"encode"
+	"io"
+
+	"github.com/boltdb/bolt"
+)
+
+const cacheSecretKey = "cache"
+
+// CacheSecret returns the random secret cache keys are derived from when no
+// master key is configured,it is created on first use and kept in vsh.db.
+func CacheSecret() ([]byte, error) {
+	if db.DBHandler == nil {
+		return nil, db.HandleIsNilErr
+	}
+	var secret []byte
+	err := db.DBHandler.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(db.DefaultClusterSecretBucket))
+		if b := bucket.Get([]byte(cacheSecretKey)); len(b) == encode.KeySize {
+			secret = append([]byte{}, b...)
+			return nil
+		}
+		secret = make([]byte, encode.KeySize)
+		if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(cacheSecretKey), secret)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
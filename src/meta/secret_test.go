@@ -0,0 +1,18 @@
+package meta
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCacheSecret(t *testing.T) {
+	defer tempDB(t)()
+	secret, err := CacheSecret()
+	if err != nil || len(secret) != 32 {
+		t.Fatal("create cache secret:", len(secret), err)
+	}
+	again, err := CacheSecret()
+	if err != nil || !bytes.Equal(again, secret) {
+		t.Fatal("cache secret changed:", err)
+	}
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+)
+
+var Formats = []Format{Table, JSON, YAML, CSV}
+
+// Record is one row of a listing,its fields are marshaled for json and yaml
+// so they need json and yaml tags.
+type Record interface {
+	Row() []string
+}
+
+func ParseFormat(s string) (Format, error) {
+	if len(s) == 0 {
+		return Table, nil
+	}
+	for _, f := range Formats {
+		if Format(strings.ToLower(s)) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %s,one of table,json,yaml,csv", s)
+}
+
+// newTabWriter returns the aligned column writer the table format uses.
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 10, 4, 6, ' ', 0)
+}
+
+// Write prints records in format,header names the columns of table and csv.
+// json and yaml always get a list,also when there is no record.
+func Write(w io.Writer, format Format, header []string, records []Record) error {
+	switch format {
+	case JSON:
+		if records == nil {
+			records = []Record{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false) //commands are full of > and &
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case YAML:
+		if records == nil {
+			records = []Record{}
+		}
+		b, err := yaml.Marshal(records)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		for _, r := range records {
+			cw.Write(r.Row())
+		}
+		cw.Flush()
+		return cw.Error()
+	case Table, "":
+		tw := newTabWriter(w)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, r := range records {
+			fmt.Fprintln(tw, strings.Join(r.Row(), "\t"))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown output format %s", format)
+}
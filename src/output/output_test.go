@@ -0,0 +1,49 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testRecord struct {
+	Host   string `json:"host" yaml:"host"`
+	Stdout string `json:"stdout" yaml:"stdout"`
+}
+
+func (r *testRecord) Row() []string { return []string{r.Host, r.Stdout} }
+
+func TestWrite(t *testing.T) {
+	header := []string{"host", "stdout"}
+	records := []Record{&testRecord{"10.0.0.1", "a > b\nc"}}
+	cases := []struct {
+		format Format
+		expect string
+	}{
+		{JSON, "[\n  {\n    \"host\": \"10.0.0.1\",\n    \"stdout\": \"a > b\\nc\"\n  }\n]\n"},
+		{YAML, "- host: 10.0.0.1\n  stdout: |-\n    a > b\n    c\n"},
+		{CSV, "host,stdout\n10.0.0.1,\"a > b\nc\"\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := Write(&buf, c.format, header, records); err != nil {
+			t.Fatal(c.format, err)
+		}
+		if buf.String() != c.expect {
+			t.Errorf("%s expect %q,got %q", c.format, c.expect, buf.String())
+		}
+	}
+	var buf bytes.Buffer
+	Write(&buf, Table, header, []Record{&testRecord{"10.0.0.1", "ok"}})
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "10.0.0.1") {
+		t.Errorf("table got %q", buf.String())
+	}
+	buf.Reset()
+	Write(&buf, JSON, header, nil)
+	if buf.String() != "[]\n" {
+		t.Errorf("empty json got %q", buf.String())
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("xml should be rejected")
+	}
+}
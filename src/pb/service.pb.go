@@ -33,6 +33,10 @@ type NodeMeta struct {
 	Tag                  string   `protobuf:"bytes,5,opt,name=tag,proto3" json:"tag,omitempty"`
 	Name                 string   `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
 	Group                string   `protobuf:"bytes,7,opt,name=group,proto3" json:"group,omitempty"`
+	Key                  string   `protobuf:"bytes,8,opt,name=key,proto3" json:"key,omitempty"`
+	Passphrase           string   `protobuf:"bytes,9,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	AgentSocket          string   `protobuf:"bytes,10,opt,name=agent_socket,json=agentSocket,proto3" json:"agent_socket,omitempty"`
+	HostKey              string   `protobuf:"bytes,11,opt,name=host_key,json=hostKey,proto3" json:"host_key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -112,6 +116,34 @@ func (m *NodeMeta) GetGroup() string {
 	return ""
 }
 
+func (m *NodeMeta) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *NodeMeta) GetPassphrase() string {
+	if m != nil {
+		return m.Passphrase
+	}
+	return ""
+}
+
+func (m *NodeMeta) GetAgentSocket() string {
+	if m != nil {
+		return m.AgentSocket
+	}
+	return ""
+}
+
+func (m *NodeMeta) GetHostKey() string {
+	if m != nil {
+		return m.HostKey
+	}
+	return ""
+}
+
 type UpdateRequest struct {
 	PubName              string      `protobuf:"bytes,1,opt,name=pub_name,json=pubName,proto3" json:"pub_name,omitempty"`
 	PubUsername          string      `protobuf:"bytes,2,opt,name=pub_username,json=pubUsername,proto3" json:"pub_username,omitempty"`
@@ -428,6 +460,8 @@ func (m *CacheRequest) GetUsername() string {
 
 type CacheResponse struct {
 	Response             int32    `protobuf:"varint,1,opt,name=response,proto3" json:"response,omitempty"`
+	Key                  []byte   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	ExpireAt             int64    `protobuf:"varint,3,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -465,6 +499,20 @@ func (m *CacheResponse) GetResponse() int32 {
 	return 0
 }
 
+func (m *CacheResponse) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *CacheResponse) GetExpireAt() int64 {
+	if m != nil {
+		return m.ExpireAt
+	}
+	return 0
+}
+
 type QueryRequest struct {
 	GroupNames           []string `protobuf:"bytes,1,rep,name=group_names,json=groupNames,proto3" json:"group_names,omitempty"`
 	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
@@ -686,6 +734,7 @@ func (m *BasicRequest) GetUsername() string {
 
 type BasicResponse struct {
 	Response             int32    `protobuf:"varint,1,opt,name=response,proto3" json:"response,omitempty"`
+	Permissions          []string `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -723,6 +772,13 @@ func (m *BasicResponse) GetResponse() int32 {
 	return 0
 }
 
+func (m *BasicResponse) GetPermissions() []string {
+	if m != nil {
+		return m.Permissions
+	}
+	return nil
+}
+
 type UserRequest struct {
 	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -763,10 +819,11 @@ func (m *UserRequest) GetUsername() string {
 }
 
 type UserResponse struct {
-	Response             map[string]int32 `protobuf:"bytes,1,rep,name=response,proto3" json:"response,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+	Response             map[string]int32  `protobuf:"bytes,1,rep,name=response,proto3" json:"response,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Roles                map[string]string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *UserResponse) Reset()         { *m = UserResponse{} }
@@ -801,238 +858,1476 @@ func (m *UserResponse) GetResponse() map[string]int32 {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*NodeMeta)(nil), "pb.NodeMeta")
-	proto.RegisterType((*UpdateRequest)(nil), "pb.UpdateRequest")
-	proto.RegisterType((*Response)(nil), "pb.Response")
-	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
-	proto.RegisterType((*DeleteResponse)(nil), "pb.DeleteResponse")
-	proto.RegisterType((*UpdateResponse)(nil), "pb.UpdateResponse")
-	proto.RegisterType((*CacheRequest)(nil), "pb.CacheRequest")
-	proto.RegisterType((*CacheResponse)(nil), "pb.CacheResponse")
-	proto.RegisterType((*QueryRequest)(nil), "pb.QueryRequest")
-	proto.RegisterType((*QueryResponse)(nil), "pb.QueryResponse")
-	proto.RegisterMapType((map[string]int32)(nil), "pb.QueryResponse.GroupMetasEntry")
-	proto.RegisterType((*DumpRequest)(nil), "pb.DumpRequest")
-	proto.RegisterType((*DumpResponse)(nil), "pb.DumpResponse")
-	proto.RegisterType((*BasicRequest)(nil), "pb.BasicRequest")
-	proto.RegisterType((*BasicResponse)(nil), "pb.BasicResponse")
-	proto.RegisterType((*UserRequest)(nil), "pb.UserRequest")
-	proto.RegisterType((*UserResponse)(nil), "pb.UserResponse")
-	proto.RegisterMapType((map[string]int32)(nil), "pb.UserResponse.ResponseEntry")
+func (m *UserResponse) GetRoles() map[string]string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
 }
 
-func init() { proto.RegisterFile("service.proto", fileDescriptor_a0b84a42fa06f626) }
+type HostKeyRequest struct {
+	Hosts                []string `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-var fileDescriptor_a0b84a42fa06f626 = []byte{
-	// 718 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0x8e, 0x9d, 0xc4, 0x4d, 0x27, 0x71, 0x7f, 0x56, 0x08, 0x4c, 0x84, 0x20, 0xb5, 0x84, 0x94,
-	0x52, 0x29, 0x15, 0xe5, 0x82, 0x8a, 0x38, 0xd0, 0x16, 0x38, 0x00, 0x55, 0x49, 0xe9, 0x39, 0x5a,
-	0xc7, 0xab, 0xb4, 0xa2, 0x89, 0x17, 0xaf, 0xdd, 0x2a, 0x4f, 0xc0, 0x85, 0xe7, 0xe0, 0x35, 0x78,
-	0x17, 0x9e, 0x04, 0xcd, 0xec, 0xae, 0x63, 0xb7, 0x52, 0x49, 0x6f, 0xb3, 0x33, 0xb3, 0xdf, 0x7e,
-	0x33, 0xdf, 0x8c, 0x0d, 0xbe, 0x12, 0xe9, 0xd5, 0xc5, 0x58, 0x0c, 0x64, 0x9a, 0x64, 0x09, 0x73,
-	0x65, 0x14, 0xfe, 0x76, 0xa0, 0x75, 0x9c, 0xc4, 0xe2, 0x8b, 0xc8, 0x38, 0x63, 0xd0, 0x38, 0x4f,
-	0x54, 0x16, 0x38, 0x3d, 0xa7, 0xbf, 0x3a, 0x24, 0x1b, 0x7d, 0x32, 0x49, 0xb3, 0xc0, 0xed, 0x39,
-	0xfd, 0xe6, 0x90, 0x6c, 0xd6, 0x85, 0x56, 0xae, 0x44, 0x3a, 0xe3, 0x53, 0x11, 0xd4, 0x29, 0xb7,
-	0x38, 0x63, 0x4c, 0x72, 0xa5, 0xae, 0x93, 0x34, 0x0e, 0x1a, 0x3a, 0x66, 0xcf, 0x6c, 0x03, 0xea,
-	0x19, 0x9f, 0x04, 0x4d, 0x72, 0xa3, 0x89, 0xe8, 0x84, 0xe2, 0xe9, 0x17, 0x09, 0xe1, 0x01, 0x34,
-	0x27, 0x69, 0x92, 0xcb, 0x60, 0x85, 0x9c, 0xfa, 0x10, 0xfe, 0x72, 0xc1, 0x3f, 0x93, 0x31, 0xcf,
-	0xc4, 0x50, 0xfc, 0xc8, 0x85, 0xca, 0xd8, 0x63, 0x68, 0xc9, 0x3c, 0x1a, 0xd1, 0x7d, 0xcd, 0x78,
-	0x45, 0xe6, 0xd1, 0x31, 0x42, 0x6c, 0x41, 0x07, 0x43, 0x05, 0x49, 0x97, 0xc2, 0x6d, 0x99, 0x47,
-	0x67, 0x96, 0xe7, 0x23, 0xc0, 0xec, 0x91, 0xbc, 0x8e, 0x4d, 0x09, 0x9e, 0xcc, 0xa3, 0x93, 0xeb,
-	0xd8, 0xc2, 0x52, 0xd1, 0x0d, 0x2a, 0x1a, 0x13, 0x4f, 0xb0, 0xee, 0x27, 0x00, 0x18, 0x22, 0x42,
-	0x23, 0x53, 0x06, 0x26, 0x7f, 0x44, 0x87, 0x45, 0xc4, 0x0a, 0xbd, 0x02, 0xf1, 0x1b, 0x9f, 0xb0,
-	0xe7, 0xb0, 0xc6, 0xf3, 0xec, 0x3c, 0x49, 0x2f, 0xb2, 0x39, 0x71, 0x32, 0x95, 0xf9, 0x85, 0x17,
-	0x59, 0xb1, 0x1d, 0x80, 0x59, 0x12, 0x8b, 0xd1, 0x54, 0x64, 0x5c, 0x05, 0xad, 0x5e, 0xbd, 0xdf,
-	0xde, 0xeb, 0x0c, 0x64, 0x34, 0xb0, 0xfa, 0x0c, 0x57, 0x67, 0xc6, 0x52, 0xe1, 0x07, 0x68, 0x0d,
-	0x85, 0x92, 0xc9, 0x4c, 0x09, 0x6c, 0x22, 0x8f, 0xe3, 0xd4, 0xca, 0x86, 0x36, 0xb6, 0x7a, 0xaa,
-	0x26, 0xa6, 0x70, 0x34, 0x17, 0x6d, 0xad, 0x97, 0xdb, 0x7a, 0x08, 0xfe, 0x91, 0xb8, 0x14, 0x8b,
-	0xae, 0x3e, 0x04, 0x8f, 0x22, 0x2a, 0x70, 0x7a, 0x75, 0x2c, 0x42, 0x9f, 0x2a, 0x9a, 0xbb, 0x55,
-	0xcd, 0xc3, 0x7d, 0x58, 0xb3, 0x20, 0x86, 0x52, 0x1f, 0x5a, 0xa9, 0xb1, 0x09, 0xc7, 0x54, 0x62,
-	0xe3, 0xc3, 0x22, 0x8a, 0x77, 0xad, 0xac, 0xf7, 0xbe, 0xfb, 0x02, 0x3a, 0x87, 0x7c, 0x7c, 0x5e,
-	0x70, 0x2f, 0x73, 0x74, 0x6e, 0x70, 0xdc, 0x01, 0xdf, 0xe4, 0x9a, 0x67, 0xba, 0x95, 0x67, 0x50,
-	0xe7, 0x05, 0xf0, 0x27, 0xe8, 0x7c, 0xcd, 0x45, 0x3a, 0xb7, 0xc0, 0xcf, 0xa0, 0xad, 0x45, 0x47,
-	0x28, 0xdb, 0x19, 0x20, 0x17, 0xce, 0xdb, 0xdd, 0xdd, 0xf9, 0xe3, 0x80, 0x6f, 0xd0, 0xcc, 0xd3,
-	0x07, 0x16, 0x4e, 0x4b, 0xad, 0x8b, 0xdc, 0xc2, 0x22, 0x2b, 0x79, 0x03, 0x9a, 0x2b, 0xd2, 0xfb,
-	0xfd, 0x2c, 0x4b, 0xe7, 0xe6, 0x45, 0x72, 0xdc, 0x98, 0x16, 0xf7, 0xce, 0x69, 0xe9, 0xbe, 0x85,
-	0xf5, 0x1b, 0x58, 0x38, 0x20, 0xdf, 0xc5, 0xdc, 0xb4, 0x09, 0x4d, 0x1c, 0x90, 0x2b, 0x7e, 0x99,
-	0x0b, 0xb3, 0xea, 0xfa, 0xb0, 0xef, 0xbe, 0x76, 0xc2, 0x6d, 0x68, 0x1f, 0xe5, 0x53, 0xb9, 0x4c,
-	0x9b, 0x8f, 0xa0, 0xa3, 0x53, 0xff, 0xdf, 0x65, 0x16, 0xc0, 0xca, 0x54, 0x28, 0xc5, 0x27, 0xb6,
-	0x67, 0xf6, 0x88, 0xc2, 0x1e, 0x70, 0x75, 0x31, 0x5e, 0x52, 0x58, 0x93, 0xbb, 0x84, 0xb0, 0xdb,
-	0xd0, 0xc6, 0x5d, 0x5b, 0x06, 0xf7, 0xa7, 0x03, 0x1d, 0x9d, 0x6b, 0x70, 0xf7, 0x6f, 0xcd, 0xe5,
-	0x53, 0xec, 0x77, 0x39, 0xa7, 0x18, 0x52, 0xad, 0x57, 0x91, 0xdf, 0x7d, 0x03, 0x7e, 0x25, 0x74,
-	0x9f, 0xf6, 0xef, 0xfd, 0x75, 0x61, 0xf3, 0x54, 0xa4, 0x57, 0x22, 0x45, 0x6d, 0x4f, 0xf5, 0x37,
-	0x9c, 0xed, 0x42, 0xe3, 0x73, 0xc2, 0x63, 0xb6, 0x49, 0x24, 0xca, 0x5f, 0xc6, 0x2e, 0x2b, 0xbb,
-	0x4c, 0xe5, 0x35, 0x36, 0x80, 0x26, 0x8d, 0x17, 0xdb, 0x28, 0x4d, 0x9a, 0xbe, 0xb0, 0x79, 0x6b,
-	0xf6, 0xc2, 0x1a, 0x7b, 0x09, 0x9e, 0xde, 0x6a, 0xfd, 0x44, 0xe5, 0x33, 0xa1, 0x9f, 0xa8, 0x2e,
-	0x7d, 0x58, 0x63, 0x3b, 0xd0, 0x40, 0xf5, 0xd9, 0x3a, 0x45, 0x17, 0x23, 0xd3, 0xdd, 0x58, 0x38,
-	0xca, 0x7c, 0x68, 0x23, 0x35, 0x9f, 0xf2, 0x22, 0x6b, 0x3e, 0x95, 0x75, 0x0d, 0x6b, 0x6c, 0x17,
-	0xbc, 0x77, 0xe3, 0xb1, 0x50, 0x4a, 0x5f, 0x28, 0x0f, 0x88, 0xbe, 0x50, 0x19, 0x03, 0xcd, 0x86,
-	0x3e, 0xac, 0xeb, 0x0b, 0x99, 0x4a, 0x6c, 0xca, 0xba, 0x85, 0xb5, 0xc8, 0xa3, 0x7f, 0xe2, 0xab,
-	0x7f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xb6, 0x80, 0x62, 0x31, 0x24, 0x07, 0x00, 0x00,
+func (m *HostKeyRequest) Reset()         { *m = HostKeyRequest{} }
+func (m *HostKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*HostKeyRequest) ProtoMessage()    {}
+func (*HostKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{16}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *HostKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HostKeyRequest.Unmarshal(m, b)
+}
+func (m *HostKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HostKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *HostKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HostKeyRequest.Merge(m, src)
+}
+func (m *HostKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_HostKeyRequest.Size(m)
+}
+func (m *HostKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HostKeyRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_HostKeyRequest proto.InternalMessageInfo
 
-// ServerNodeServiceClient is the client API for ServerNodeService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ServerNodeServiceClient interface {
-	Load(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
-	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
-	Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpResponse, error)
-	Cache(ctx context.Context, in *CacheRequest, opts ...grpc.CallOption) (*CacheResponse, error)
-	Access(ctx context.Context, in *BasicRequest, opts ...grpc.CallOption) (*BasicResponse, error)
-	User(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserResponse, error)
+func (m *HostKeyRequest) GetHosts() []string {
+	if m != nil {
+		return m.Hosts
+	}
+	return nil
 }
 
-type serverNodeServiceClient struct {
-	cc *grpc.ClientConn
+func (m *HostKeyRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
 }
 
-func NewServerNodeServiceClient(cc *grpc.ClientConn) ServerNodeServiceClient {
-	return &serverNodeServiceClient{cc}
+type HostKeyResponse struct {
+	Response             []*Response `protobuf:"bytes,1,rep,name=response,proto3" json:"response,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
-func (c *serverNodeServiceClient) Load(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
-	out := new(UpdateResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Load", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *HostKeyResponse) Reset()         { *m = HostKeyResponse{} }
+func (m *HostKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*HostKeyResponse) ProtoMessage()    {}
+func (*HostKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{17}
 }
 
-func (c *serverNodeServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
-	out := new(QueryResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Query", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *HostKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HostKeyResponse.Unmarshal(m, b)
+}
+func (m *HostKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HostKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *HostKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HostKeyResponse.Merge(m, src)
+}
+func (m *HostKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_HostKeyResponse.Size(m)
+}
+func (m *HostKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HostKeyResponse.DiscardUnknown(m)
 }
 
-func (c *serverNodeServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
-	out := new(DeleteResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Delete", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_HostKeyResponse proto.InternalMessageInfo
+
+func (m *HostKeyResponse) GetResponse() []*Response {
+	if m != nil {
+		return m.Response
 	}
-	return out, nil
+	return nil
 }
 
-func (c *serverNodeServiceClient) Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpResponse, error) {
-	out := new(DumpResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Dump", in, out, opts...)
-	if err != nil {
-		return nil, err
+// ProxyRequest carries a terminal session relayed by the server. The first
+// message names the node and the pty,later ones carry stdin or a window change.
+type ProxyRequest struct {
+	Host                 string   `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Term                 string   `protobuf:"bytes,3,opt,name=term,proto3" json:"term,omitempty"`
+	Height               int32    `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Width                int32    `protobuf:"varint,5,opt,name=width,proto3" json:"width,omitempty"`
+	Data                 []byte   `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProxyRequest) Reset()         { *m = ProxyRequest{} }
+func (m *ProxyRequest) String() string { return proto.CompactTextString(m) }
+func (*ProxyRequest) ProtoMessage()    {}
+func (*ProxyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{18}
+}
+
+func (m *ProxyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProxyRequest.Unmarshal(m, b)
+}
+func (m *ProxyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProxyRequest.Marshal(b, m, deterministic)
+}
+func (m *ProxyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProxyRequest.Merge(m, src)
+}
+func (m *ProxyRequest) XXX_Size() int {
+	return xxx_messageInfo_ProxyRequest.Size(m)
+}
+func (m *ProxyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProxyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProxyRequest proto.InternalMessageInfo
+
+func (m *ProxyRequest) GetHost() string {
+	if m != nil {
+		return m.Host
 	}
-	return out, nil
+	return ""
 }
 
-func (c *serverNodeServiceClient) Cache(ctx context.Context, in *CacheRequest, opts ...grpc.CallOption) (*CacheResponse, error) {
-	out := new(CacheResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Cache", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ProxyRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
 	}
-	return out, nil
+	return ""
 }
 
-func (c *serverNodeServiceClient) Access(ctx context.Context, in *BasicRequest, opts ...grpc.CallOption) (*BasicResponse, error) {
-	out := new(BasicResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Access", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ProxyRequest) GetTerm() string {
+	if m != nil {
+		return m.Term
 	}
-	return out, nil
+	return ""
 }
 
-func (c *serverNodeServiceClient) User(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
-	out := new(UserResponse)
-	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/User", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ProxyRequest) GetHeight() int32 {
+	if m != nil {
+		return m.Height
 	}
-	return out, nil
+	return 0
 }
 
-// ServerNodeServiceServer is the server API for ServerNodeService service.
-type ServerNodeServiceServer interface {
-	Load(context.Context, *UpdateRequest) (*UpdateResponse, error)
-	Query(context.Context, *QueryRequest) (*QueryResponse, error)
-	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
-	Dump(context.Context, *DumpRequest) (*DumpResponse, error)
-	Cache(context.Context, *CacheRequest) (*CacheResponse, error)
-	Access(context.Context, *BasicRequest) (*BasicResponse, error)
-	User(context.Context, *UserRequest) (*UserResponse, error)
+func (m *ProxyRequest) GetWidth() int32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
 }
 
-// UnimplementedServerNodeServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedServerNodeServiceServer struct {
+func (m *ProxyRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
 }
 
-func (*UnimplementedServerNodeServiceServer) Load(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Load not implemented")
+type ProxyResponse struct {
+	Stdout               []byte   `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr               []byte   `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	Exit                 bool     `protobuf:"varint,3,opt,name=exit,proto3" json:"exit,omitempty"`
+	ExitStatus           int32    `protobuf:"varint,4,opt,name=exit_status,json=exitStatus,proto3" json:"exit_status,omitempty"`
+	Msg                  string   `protobuf:"bytes,5,opt,name=msg,proto3" json:"msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (*UnimplementedServerNodeServiceServer) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+
+func (m *ProxyResponse) Reset()         { *m = ProxyResponse{} }
+func (m *ProxyResponse) String() string { return proto.CompactTextString(m) }
+func (*ProxyResponse) ProtoMessage()    {}
+func (*ProxyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{19}
 }
-func (*UnimplementedServerNodeServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+
+func (m *ProxyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProxyResponse.Unmarshal(m, b)
 }
-func (*UnimplementedServerNodeServiceServer) Dump(ctx context.Context, req *DumpRequest) (*DumpResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Dump not implemented")
+func (m *ProxyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProxyResponse.Marshal(b, m, deterministic)
 }
-func (*UnimplementedServerNodeServiceServer) Cache(ctx context.Context, req *CacheRequest) (*CacheResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Cache not implemented")
+func (m *ProxyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProxyResponse.Merge(m, src)
 }
-func (*UnimplementedServerNodeServiceServer) Access(ctx context.Context, req *BasicRequest) (*BasicResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Access not implemented")
+func (m *ProxyResponse) XXX_Size() int {
+	return xxx_messageInfo_ProxyResponse.Size(m)
 }
-func (*UnimplementedServerNodeServiceServer) User(ctx context.Context, req *UserRequest) (*UserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method User not implemented")
+func (m *ProxyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProxyResponse.DiscardUnknown(m)
 }
 
-func RegisterServerNodeServiceServer(s *grpc.Server, srv ServerNodeServiceServer) {
-	s.RegisterService(&_ServerNodeService_serviceDesc, srv)
-}
+var xxx_messageInfo_ProxyResponse proto.InternalMessageInfo
 
-func _ServerNodeService_Load_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ServerNodeServiceServer).Load(ctx, in)
+func (m *ProxyResponse) GetStdout() []byte {
+	if m != nil {
+		return m.Stdout
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.ServerNodeService/Load",
+	return nil
+}
+
+func (m *ProxyResponse) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServerNodeServiceServer).Load(ctx, req.(*UpdateRequest))
+	return nil
+}
+
+func (m *ProxyResponse) GetExit() bool {
+	if m != nil {
+		return m.Exit
 	}
-	return interceptor(ctx, in, info, handler)
+	return false
 }
 
-func _ServerNodeService_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(QueryRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *ProxyResponse) GetExitStatus() int32 {
+	if m != nil {
+		return m.ExitStatus
 	}
-	if interceptor == nil {
-		return srv.(ServerNodeServiceServer).Query(ctx, in)
+	return 0
+}
+
+func (m *ProxyResponse) GetMsg() string {
+	if m != nil {
+		return m.Msg
 	}
-	info := &grpc.UnaryServerInfo{
+	return ""
+}
+
+type AuditEntry struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	User                 string   `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Addr                 string   `protobuf:"bytes,3,opt,name=addr,proto3" json:"addr,omitempty"`
+	Action               string   `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Targets              []string `protobuf:"bytes,5,rep,name=targets,proto3" json:"targets,omitempty"`
+	Result               string   `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	Detail               string   `protobuf:"bytes,7,opt,name=detail,proto3" json:"detail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEntry) Reset()         { *m = AuditEntry{} }
+func (m *AuditEntry) String() string { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()    {}
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{20}
+}
+
+func (m *AuditEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEntry.Unmarshal(m, b)
+}
+func (m *AuditEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEntry.Marshal(b, m, deterministic)
+}
+func (m *AuditEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEntry.Merge(m, src)
+}
+func (m *AuditEntry) XXX_Size() int {
+	return xxx_messageInfo_AuditEntry.Size(m)
+}
+func (m *AuditEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEntry proto.InternalMessageInfo
+
+func (m *AuditEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AuditEntry) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetTargets() []string {
+	if m != nil {
+		return m.Targets
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetResult() string {
+	if m != nil {
+		return m.Result
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+// ReportRequest records an action the client ran itself,such as run or login.
+type ReportRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Action               string   `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Targets              []string `protobuf:"bytes,3,rep,name=targets,proto3" json:"targets,omitempty"`
+	Result               string   `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	Detail               string   `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReportRequest) Reset()         { *m = ReportRequest{} }
+func (m *ReportRequest) String() string { return proto.CompactTextString(m) }
+func (*ReportRequest) ProtoMessage()    {}
+func (*ReportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{21}
+}
+
+func (m *ReportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportRequest.Unmarshal(m, b)
+}
+func (m *ReportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportRequest.Marshal(b, m, deterministic)
+}
+func (m *ReportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportRequest.Merge(m, src)
+}
+func (m *ReportRequest) XXX_Size() int {
+	return xxx_messageInfo_ReportRequest.Size(m)
+}
+func (m *ReportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportRequest proto.InternalMessageInfo
+
+func (m *ReportRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReportRequest) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *ReportRequest) GetTargets() []string {
+	if m != nil {
+		return m.Targets
+	}
+	return nil
+}
+
+func (m *ReportRequest) GetResult() string {
+	if m != nil {
+		return m.Result
+	}
+	return ""
+}
+
+func (m *ReportRequest) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+type ReportResponse struct {
+	Response             int32    `protobuf:"varint,1,opt,name=response,proto3" json:"response,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReportResponse) Reset()         { *m = ReportResponse{} }
+func (m *ReportResponse) String() string { return proto.CompactTextString(m) }
+func (*ReportResponse) ProtoMessage()    {}
+func (*ReportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{22}
+}
+
+func (m *ReportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportResponse.Unmarshal(m, b)
+}
+func (m *ReportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportResponse.Marshal(b, m, deterministic)
+}
+func (m *ReportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportResponse.Merge(m, src)
+}
+func (m *ReportResponse) XXX_Size() int {
+	return xxx_messageInfo_ReportResponse.Size(m)
+}
+func (m *ReportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportResponse proto.InternalMessageInfo
+
+func (m *ReportResponse) GetResponse() int32 {
+	if m != nil {
+		return m.Response
+	}
+	return 0
+}
+
+type AuditRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	User                 string   `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Node                 string   `protobuf:"bytes,3,opt,name=node,proto3" json:"node,omitempty"`
+	Since                int64    `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until                int64    `protobuf:"varint,5,opt,name=until,proto3" json:"until,omitempty"`
+	Limit                int32    `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditRequest) Reset()         { *m = AuditRequest{} }
+func (m *AuditRequest) String() string { return proto.CompactTextString(m) }
+func (*AuditRequest) ProtoMessage()    {}
+func (*AuditRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{23}
+}
+
+func (m *AuditRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditRequest.Unmarshal(m, b)
+}
+func (m *AuditRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditRequest.Marshal(b, m, deterministic)
+}
+func (m *AuditRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditRequest.Merge(m, src)
+}
+func (m *AuditRequest) XXX_Size() int {
+	return xxx_messageInfo_AuditRequest.Size(m)
+}
+func (m *AuditRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditRequest proto.InternalMessageInfo
+
+func (m *AuditRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *AuditRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *AuditRequest) GetNode() string {
+	if m != nil {
+		return m.Node
+	}
+	return ""
+}
+
+func (m *AuditRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *AuditRequest) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *AuditRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AuditResponse struct {
+	Entries              []*AuditEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *AuditResponse) Reset()         { *m = AuditResponse{} }
+func (m *AuditResponse) String() string { return proto.CompactTextString(m) }
+func (*AuditResponse) ProtoMessage()    {}
+func (*AuditResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{24}
+}
+
+func (m *AuditResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditResponse.Unmarshal(m, b)
+}
+func (m *AuditResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditResponse.Marshal(b, m, deterministic)
+}
+func (m *AuditResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditResponse.Merge(m, src)
+}
+func (m *AuditResponse) XXX_Size() int {
+	return xxx_messageInfo_AuditResponse.Size(m)
+}
+func (m *AuditResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditResponse proto.InternalMessageInfo
+
+func (m *AuditResponse) GetEntries() []*AuditEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+// ExecRequest runs cmd on hosts from the server,so command policies apply.
+type ExecRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Hosts                []string `protobuf:"bytes,2,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Cmd                  string   `protobuf:"bytes,3,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Parallel             int32    `protobuf:"varint,4,opt,name=parallel,proto3" json:"parallel,omitempty"`
+	Timeout              int32    `protobuf:"varint,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Stream               bool     `protobuf:"varint,6,opt,name=stream,proto3" json:"stream,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExecRequest) Reset()         { *m = ExecRequest{} }
+func (m *ExecRequest) String() string { return proto.CompactTextString(m) }
+func (*ExecRequest) ProtoMessage()    {}
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{25}
+}
+
+func (m *ExecRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecRequest.Unmarshal(m, b)
+}
+func (m *ExecRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecRequest.Marshal(b, m, deterministic)
+}
+func (m *ExecRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecRequest.Merge(m, src)
+}
+func (m *ExecRequest) XXX_Size() int {
+	return xxx_messageInfo_ExecRequest.Size(m)
+}
+func (m *ExecRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecRequest proto.InternalMessageInfo
+
+func (m *ExecRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ExecRequest) GetHosts() []string {
+	if m != nil {
+		return m.Hosts
+	}
+	return nil
+}
+
+func (m *ExecRequest) GetCmd() string {
+	if m != nil {
+		return m.Cmd
+	}
+	return ""
+}
+
+func (m *ExecRequest) GetParallel() int32 {
+	if m != nil {
+		return m.Parallel
+	}
+	return 0
+}
+
+func (m *ExecRequest) GetTimeout() int32 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *ExecRequest) GetStream() bool {
+	if m != nil {
+		return m.Stream
+	}
+	return false
+}
+
+// ExecResponse is the result of one host,sent as soon as the host finishes.
+// With stream set every line of output comes first in its own partial response.
+type ExecResponse struct {
+	Host                 string   `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Output               []byte   `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Duration             int64    `protobuf:"varint,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Stderr               []byte   `protobuf:"bytes,5,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	ExitStatus           int32    `protobuf:"varint,6,opt,name=exit_status,json=exitStatus,proto3" json:"exit_status,omitempty"`
+	Signal               string   `protobuf:"bytes,7,opt,name=signal,proto3" json:"signal,omitempty"`
+	Kind                 string   `protobuf:"bytes,8,opt,name=kind,proto3" json:"kind,omitempty"`
+	Partial              bool     `protobuf:"varint,9,opt,name=partial,proto3" json:"partial,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExecResponse) Reset()         { *m = ExecResponse{} }
+func (m *ExecResponse) String() string { return proto.CompactTextString(m) }
+func (*ExecResponse) ProtoMessage()    {}
+func (*ExecResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{26}
+}
+
+func (m *ExecResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecResponse.Unmarshal(m, b)
+}
+func (m *ExecResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecResponse.Marshal(b, m, deterministic)
+}
+func (m *ExecResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecResponse.Merge(m, src)
+}
+func (m *ExecResponse) XXX_Size() int {
+	return xxx_messageInfo_ExecResponse.Size(m)
+}
+func (m *ExecResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecResponse proto.InternalMessageInfo
+
+func (m *ExecResponse) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *ExecResponse) GetOutput() []byte {
+	if m != nil {
+		return m.Output
+	}
+	return nil
+}
+
+func (m *ExecResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ExecResponse) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *ExecResponse) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
+	}
+	return nil
+}
+
+func (m *ExecResponse) GetExitStatus() int32 {
+	if m != nil {
+		return m.ExitStatus
+	}
+	return 0
+}
+
+func (m *ExecResponse) GetSignal() string {
+	if m != nil {
+		return m.Signal
+	}
+	return ""
+}
+
+func (m *ExecResponse) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *ExecResponse) GetPartial() bool {
+	if m != nil {
+		return m.Partial
+	}
+	return false
+}
+
+// GrantEntry is a temporary grant,expire_at stays 0 until it is approved.
+type GrantEntry struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	User                 string   `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Groups               []string `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
+	Tags                 []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	Addresses            []string `protobuf:"bytes,5,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Roles                []string `protobuf:"bytes,6,rep,name=roles,proto3" json:"roles,omitempty"`
+	Reason               string   `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	RequestedBy          string   `protobuf:"bytes,8,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	ApprovedBy           string   `protobuf:"bytes,9,opt,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"`
+	Created              int64    `protobuf:"varint,10,opt,name=created,proto3" json:"created,omitempty"`
+	Duration             int64    `protobuf:"varint,11,opt,name=duration,proto3" json:"duration,omitempty"`
+	ExpireAt             int64    `protobuf:"varint,12,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GrantEntry) Reset()         { *m = GrantEntry{} }
+func (m *GrantEntry) String() string { return proto.CompactTextString(m) }
+func (*GrantEntry) ProtoMessage()    {}
+func (*GrantEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{27}
+}
+
+func (m *GrantEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GrantEntry.Unmarshal(m, b)
+}
+func (m *GrantEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GrantEntry.Marshal(b, m, deterministic)
+}
+func (m *GrantEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GrantEntry.Merge(m, src)
+}
+func (m *GrantEntry) XXX_Size() int {
+	return xxx_messageInfo_GrantEntry.Size(m)
+}
+func (m *GrantEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_GrantEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GrantEntry proto.InternalMessageInfo
+
+func (m *GrantEntry) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *GrantEntry) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *GrantEntry) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *GrantEntry) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *GrantEntry) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *GrantEntry) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *GrantEntry) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *GrantEntry) GetRequestedBy() string {
+	if m != nil {
+		return m.RequestedBy
+	}
+	return ""
+}
+
+func (m *GrantEntry) GetApprovedBy() string {
+	if m != nil {
+		return m.ApprovedBy
+	}
+	return ""
+}
+
+func (m *GrantEntry) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *GrantEntry) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *GrantEntry) GetExpireAt() int64 {
+	if m != nil {
+		return m.ExpireAt
+	}
+	return 0
+}
+
+// GrantRequest adds,approves,revokes or lists grants,RequestAccess only reads
+// the grant fields and asks for the caller itself.
+type GrantRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Action               string   `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Id                   int64    `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	User                 string   `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	Groups               []string `protobuf:"bytes,5,rep,name=groups,proto3" json:"groups,omitempty"`
+	Tags                 []string `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Addresses            []string `protobuf:"bytes,7,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Roles                []string `protobuf:"bytes,8,rep,name=roles,proto3" json:"roles,omitempty"`
+	Duration             int64    `protobuf:"varint,9,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason               string   `protobuf:"bytes,10,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GrantRequest) Reset()         { *m = GrantRequest{} }
+func (m *GrantRequest) String() string { return proto.CompactTextString(m) }
+func (*GrantRequest) ProtoMessage()    {}
+func (*GrantRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{28}
+}
+
+func (m *GrantRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GrantRequest.Unmarshal(m, b)
+}
+func (m *GrantRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GrantRequest.Marshal(b, m, deterministic)
+}
+func (m *GrantRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GrantRequest.Merge(m, src)
+}
+func (m *GrantRequest) XXX_Size() int {
+	return xxx_messageInfo_GrantRequest.Size(m)
+}
+func (m *GrantRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GrantRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GrantRequest proto.InternalMessageInfo
+
+func (m *GrantRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *GrantRequest) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *GrantRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *GrantRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *GrantRequest) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *GrantRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *GrantRequest) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *GrantRequest) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *GrantRequest) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *GrantRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type GrantResponse struct {
+	Grants               []*GrantEntry `protobuf:"bytes,1,rep,name=grants,proto3" json:"grants,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GrantResponse) Reset()         { *m = GrantResponse{} }
+func (m *GrantResponse) String() string { return proto.CompactTextString(m) }
+func (*GrantResponse) ProtoMessage()    {}
+func (*GrantResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{29}
+}
+
+func (m *GrantResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GrantResponse.Unmarshal(m, b)
+}
+func (m *GrantResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GrantResponse.Marshal(b, m, deterministic)
+}
+func (m *GrantResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GrantResponse.Merge(m, src)
+}
+func (m *GrantResponse) XXX_Size() int {
+	return xxx_messageInfo_GrantResponse.Size(m)
+}
+func (m *GrantResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GrantResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GrantResponse proto.InternalMessageInfo
+
+func (m *GrantResponse) GetGrants() []*GrantEntry {
+	if m != nil {
+		return m.Grants
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*NodeMeta)(nil), "pb.NodeMeta")
+	proto.RegisterType((*UpdateRequest)(nil), "pb.UpdateRequest")
+	proto.RegisterType((*Response)(nil), "pb.Response")
+	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "pb.DeleteResponse")
+	proto.RegisterType((*UpdateResponse)(nil), "pb.UpdateResponse")
+	proto.RegisterType((*CacheRequest)(nil), "pb.CacheRequest")
+	proto.RegisterType((*CacheResponse)(nil), "pb.CacheResponse")
+	proto.RegisterType((*QueryRequest)(nil), "pb.QueryRequest")
+	proto.RegisterType((*QueryResponse)(nil), "pb.QueryResponse")
+	proto.RegisterMapType((map[string]int32)(nil), "pb.QueryResponse.GroupMetasEntry")
+	proto.RegisterType((*DumpRequest)(nil), "pb.DumpRequest")
+	proto.RegisterType((*DumpResponse)(nil), "pb.DumpResponse")
+	proto.RegisterType((*BasicRequest)(nil), "pb.BasicRequest")
+	proto.RegisterType((*BasicResponse)(nil), "pb.BasicResponse")
+	proto.RegisterType((*UserRequest)(nil), "pb.UserRequest")
+	proto.RegisterType((*UserResponse)(nil), "pb.UserResponse")
+	proto.RegisterMapType((map[string]int32)(nil), "pb.UserResponse.ResponseEntry")
+	proto.RegisterMapType((map[string]string)(nil), "pb.UserResponse.RolesEntry")
+	proto.RegisterType((*HostKeyRequest)(nil), "pb.HostKeyRequest")
+	proto.RegisterType((*HostKeyResponse)(nil), "pb.HostKeyResponse")
+	proto.RegisterType((*ProxyRequest)(nil), "pb.ProxyRequest")
+	proto.RegisterType((*ProxyResponse)(nil), "pb.ProxyResponse")
+	proto.RegisterType((*AuditEntry)(nil), "pb.AuditEntry")
+	proto.RegisterType((*ReportRequest)(nil), "pb.ReportRequest")
+	proto.RegisterType((*ReportResponse)(nil), "pb.ReportResponse")
+	proto.RegisterType((*AuditRequest)(nil), "pb.AuditRequest")
+	proto.RegisterType((*AuditResponse)(nil), "pb.AuditResponse")
+	proto.RegisterType((*ExecRequest)(nil), "pb.ExecRequest")
+	proto.RegisterType((*ExecResponse)(nil), "pb.ExecResponse")
+	proto.RegisterType((*GrantEntry)(nil), "pb.GrantEntry")
+	proto.RegisterType((*GrantRequest)(nil), "pb.GrantRequest")
+	proto.RegisterType((*GrantResponse)(nil), "pb.GrantResponse")
+}
+
+func init() { proto.RegisterFile("service.proto", fileDescriptor_a0b84a42fa06f626) }
+
+var fileDescriptor_a0b84a42fa06f626 = []byte{
+	// 1576 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0xcb, 0x93, 0xdb, 0x44,
+	0x13, 0x8f, 0x2c, 0xcb, 0x6b, 0xb7, 0xed, 0xdd, 0xb5, 0xbe, 0xd4, 0x7e, 0xfa, 0x94, 0x54, 0xbe,
+	0x8d, 0xaa, 0xa0, 0x16, 0x02, 0x9b, 0x07, 0x14, 0x84, 0xa4, 0x38, 0x64, 0x93, 0x10, 0xaa, 0x42,
+	0x52, 0x41, 0x4b, 0x2e, 0x5c, 0x5c, 0x63, 0x6b, 0xca, 0xab, 0x5a, 0x5b, 0x12, 0x33, 0xa3, 0xdd,
+	0xf5, 0x3f, 0xc0, 0x09, 0x6e, 0x14, 0x57, 0xee, 0xfc, 0x13, 0xb9, 0xf1, 0xcf, 0x70, 0xe5, 0xc4,
+	0x89, 0xea, 0x79, 0x48, 0xa3, 0x7d, 0xc5, 0x29, 0x4e, 0x9e, 0xee, 0xe9, 0xe9, 0xe9, 0xf9, 0xf5,
+	0x53, 0x86, 0x21, 0xa7, 0xec, 0x28, 0x9d, 0xd2, 0xdd, 0x82, 0xe5, 0x22, 0xf7, 0x5b, 0xc5, 0x24,
+	0xfa, 0xb5, 0x05, 0xdd, 0x97, 0x79, 0x42, 0x5f, 0x50, 0x41, 0x7c, 0x1f, 0xda, 0x07, 0x39, 0x17,
+	0x81, 0xb3, 0xed, 0xec, 0xf4, 0x62, 0xb9, 0x46, 0x5e, 0x91, 0x33, 0x11, 0xb4, 0xb6, 0x9d, 0x1d,
+	0x2f, 0x96, 0x6b, 0x3f, 0x84, 0x6e, 0xc9, 0x29, 0xcb, 0xc8, 0x82, 0x06, 0xae, 0x94, 0xad, 0x68,
+	0xdc, 0x2b, 0x08, 0xe7, 0xc7, 0x39, 0x4b, 0x82, 0xb6, 0xda, 0x33, 0xb4, 0xbf, 0x09, 0xae, 0x20,
+	0xb3, 0xc0, 0x93, 0x6c, 0x5c, 0xa2, 0x76, 0xa9, 0xa5, 0xa3, 0x6e, 0x94, 0x1a, 0xae, 0x82, 0x37,
+	0x63, 0x79, 0x59, 0x04, 0x6b, 0x92, 0xa9, 0x08, 0x3c, 0x7b, 0x48, 0x97, 0x41, 0x57, 0x9d, 0x3d,
+	0xa4, 0x4b, 0xff, 0x06, 0x00, 0x6a, 0x2e, 0x0e, 0x18, 0xe1, 0x34, 0xe8, 0xc9, 0x0d, 0x8b, 0xe3,
+	0xdf, 0x84, 0x01, 0x99, 0xd1, 0x4c, 0x8c, 0x79, 0x3e, 0x3d, 0xa4, 0x22, 0x00, 0x29, 0xd1, 0x97,
+	0xbc, 0x7d, 0xc9, 0xf2, 0xff, 0x07, 0x5d, 0x7c, 0xe4, 0x18, 0x35, 0xf7, 0xe5, 0xf6, 0x1a, 0xd2,
+	0xcf, 0xe9, 0x32, 0xfa, 0xa9, 0x05, 0xc3, 0xd7, 0x45, 0x42, 0x04, 0x8d, 0xe9, 0x0f, 0x25, 0xe5,
+	0x52, 0xb8, 0x28, 0x27, 0x63, 0x69, 0xaf, 0x42, 0x68, 0xad, 0x28, 0x27, 0x2f, 0xd1, 0xe4, 0x9b,
+	0x30, 0xc0, 0xad, 0x0a, 0x94, 0x96, 0xba, 0xaa, 0x28, 0x27, 0xaf, 0x0d, 0x2e, 0xff, 0x05, 0x94,
+	0x1e, 0x17, 0xc7, 0x89, 0x86, 0xac, 0x53, 0x94, 0x93, 0x57, 0xc7, 0x89, 0x51, 0x2b, 0x41, 0x6e,
+	0x4b, 0x90, 0x51, 0xf0, 0x15, 0xe2, 0x7c, 0x1d, 0x00, 0xb7, 0x24, 0x00, 0x63, 0x0d, 0x1b, 0x0a,
+	0x3f, 0x93, 0x88, 0x68, 0x8d, 0x88, 0x68, 0xa7, 0xd2, 0xf8, 0x1d, 0x99, 0xf9, 0xef, 0xc1, 0x3a,
+	0x29, 0xc5, 0x41, 0xce, 0x52, 0xb1, 0x94, 0x36, 0x69, 0x24, 0x87, 0x15, 0x17, 0xad, 0xf2, 0x6f,
+	0x01, 0x64, 0x79, 0x42, 0xc7, 0x0b, 0x2a, 0x08, 0x0f, 0xba, 0xdb, 0xee, 0x4e, 0xff, 0xde, 0x60,
+	0xb7, 0x98, 0xec, 0x9a, 0x78, 0x88, 0x7b, 0x99, 0x5e, 0xf1, 0xe8, 0x2b, 0xe8, 0xc6, 0x94, 0x17,
+	0x79, 0xc6, 0x29, 0x3a, 0x8d, 0x24, 0x09, 0x33, 0x61, 0x82, 0x6b, 0x74, 0xcf, 0x82, 0xcf, 0xf4,
+	0xc3, 0x71, 0x59, 0xbb, 0xd1, 0xb5, 0xdc, 0x18, 0x3d, 0x86, 0xe1, 0x13, 0x3a, 0xa7, 0x35, 0xaa,
+	0x5b, 0xd0, 0x91, 0x3b, 0x3c, 0x70, 0xb6, 0x5d, 0x7c, 0x84, 0xa2, 0x1a, 0x31, 0xd6, 0x6a, 0xc6,
+	0x58, 0xf4, 0x00, 0xd6, 0x8d, 0x12, 0x6d, 0xd2, 0x0e, 0x74, 0x99, 0x5e, 0x07, 0x4e, 0xfd, 0x12,
+	0xb3, 0x1f, 0x57, 0xbb, 0x78, 0xd6, 0xb8, 0xf5, 0x9d, 0xcf, 0x7e, 0x08, 0x83, 0xc7, 0x64, 0x7a,
+	0x50, 0xd9, 0x6e, 0xdb, 0xe8, 0x9c, 0xb2, 0xf1, 0x7b, 0x18, 0x6a, 0x59, 0x7d, 0x4d, 0xd8, 0xb8,
+	0x06, 0xfd, 0x5c, 0xd1, 0x26, 0xb8, 0xf1, 0x9d, 0x03, 0x15, 0xdc, 0xd7, 0xa0, 0x47, 0x4f, 0x8a,
+	0x94, 0xd1, 0x31, 0x11, 0x12, 0x41, 0x37, 0xee, 0x2a, 0xc6, 0x23, 0x11, 0x3d, 0x87, 0xc1, 0xb7,
+	0x25, 0x65, 0x4b, 0x63, 0xc7, 0xff, 0xa1, 0xaf, 0x62, 0x04, 0x6f, 0x36, 0x40, 0x82, 0x64, 0x61,
+	0x78, 0x5e, 0x0e, 0xe6, 0x1b, 0x07, 0x86, 0x5a, 0x9b, 0xb6, 0x66, 0xcf, 0xa8, 0x53, 0x91, 0xa1,
+	0x30, 0xb9, 0x89, 0x98, 0x34, 0xe4, 0x76, 0x65, 0x18, 0xca, 0xf0, 0x78, 0x9a, 0x09, 0xb6, 0xd4,
+	0x37, 0x4a, 0xc6, 0xa9, 0xe0, 0x6a, 0x5d, 0x1a, 0x5c, 0xe1, 0x97, 0xb0, 0x71, 0x4a, 0x97, 0x41,
+	0xc4, 0xa9, 0xd3, 0xfd, 0x2a, 0x78, 0x47, 0x64, 0x5e, 0x52, 0x5d, 0x89, 0x14, 0xf1, 0xa0, 0x75,
+	0xdf, 0x89, 0x3e, 0x80, 0xfe, 0x93, 0x72, 0x51, 0xac, 0xe2, 0x95, 0x27, 0x30, 0x50, 0xa2, 0x2b,
+	0x38, 0x25, 0x80, 0xb5, 0x05, 0xe5, 0x9c, 0xcc, 0x0c, 0x66, 0x86, 0xc4, 0x38, 0xd8, 0x23, 0x3c,
+	0x9d, 0xae, 0x72, 0xe3, 0x0b, 0x18, 0x6a, 0xd9, 0x15, 0xae, 0xdc, 0x86, 0x7e, 0x41, 0xd9, 0x22,
+	0xe5, 0x3c, 0xcd, 0x33, 0x05, 0x5b, 0x2f, 0xb6, 0x59, 0xf8, 0x56, 0x4c, 0xde, 0x55, 0x6e, 0xfe,
+	0xcb, 0x81, 0x81, 0x92, 0xd5, 0xda, 0x1f, 0x9c, 0x09, 0xf4, 0x1b, 0xe8, 0x11, 0x5b, 0xa6, 0x8a,
+	0x7a, 0xe5, 0xd1, 0xda, 0xb2, 0xbb, 0xe0, 0xb1, 0x7c, 0x4e, 0x8d, 0x2b, 0xaf, 0x9d, 0x3d, 0x88,
+	0xbb, 0xea, 0x94, 0x92, 0x0c, 0x1f, 0xc2, 0xb0, 0xa1, 0xed, 0x5d, 0x7c, 0x1a, 0xde, 0x07, 0xa8,
+	0x35, 0xbe, 0xed, 0x64, 0xcf, 0x8e, 0x86, 0x3d, 0x58, 0xff, 0x5a, 0xd5, 0x70, 0x03, 0xd2, 0x55,
+	0xf0, 0xb0, 0xaa, 0x9b, 0xc4, 0x50, 0xc4, 0xa5, 0x39, 0xf1, 0x10, 0x36, 0x2a, 0x1d, 0xef, 0x5c,
+	0x25, 0x7e, 0x71, 0x60, 0xf0, 0x8a, 0xe5, 0x27, 0xd5, 0xfd, 0xe7, 0xb5, 0xd5, 0x4b, 0x6e, 0x47,
+	0x79, 0x41, 0xd9, 0x42, 0x17, 0x4e, 0xb9, 0xc6, 0x32, 0x79, 0x40, 0xd3, 0xd9, 0x81, 0xe9, 0x11,
+	0x9a, 0xc2, 0xb7, 0x1d, 0xa7, 0x89, 0x38, 0x90, 0xdd, 0xc1, 0x8b, 0x15, 0x81, 0x1a, 0x12, 0x22,
+	0x88, 0xec, 0x0b, 0x83, 0x58, 0xae, 0xa3, 0x1f, 0x1d, 0x18, 0x6a, 0xb3, 0xf4, 0x93, 0xb6, 0xa0,
+	0xc3, 0x45, 0x92, 0x97, 0xca, 0xb2, 0x41, 0xac, 0x29, 0xcd, 0xa7, 0x8c, 0xe9, 0x82, 0xa4, 0x29,
+	0xd4, 0x4a, 0x4f, 0x52, 0x55, 0x8e, 0xba, 0xb1, 0x5c, 0x63, 0xe9, 0xc1, 0xdf, 0x31, 0x17, 0x44,
+	0x94, 0x5c, 0x1b, 0x07, 0xc8, 0xda, 0x97, 0x1c, 0xd3, 0x18, 0xbc, 0xaa, 0x31, 0x44, 0xbf, 0x3b,
+	0x00, 0x8f, 0xca, 0x24, 0x15, 0xca, 0xb7, 0xf8, 0xda, 0x54, 0x87, 0xaf, 0x1b, 0xcb, 0x35, 0xf2,
+	0x64, 0xdf, 0x52, 0xc8, 0xc8, 0x75, 0xd5, 0x75, 0x5c, 0xab, 0xeb, 0x6c, 0x41, 0x87, 0x4c, 0x45,
+	0x9a, 0x67, 0x7a, 0xd4, 0xd0, 0x14, 0xa6, 0xae, 0x20, 0x6c, 0x46, 0x05, 0x0f, 0x3c, 0xe9, 0x73,
+	0x43, 0xe2, 0x09, 0x46, 0x79, 0x39, 0x17, 0xa6, 0x67, 0x2a, 0x0a, 0xf9, 0x09, 0x15, 0x24, 0x9d,
+	0xeb, 0x5e, 0xa9, 0xa9, 0xe8, 0x67, 0x07, 0xa3, 0x18, 0x9b, 0xf3, 0x0a, 0x29, 0x67, 0xd9, 0xd3,
+	0xba, 0xc8, 0x1e, 0xf7, 0x22, 0x7b, 0xda, 0x17, 0xd8, 0xe3, 0x35, 0xec, 0xf9, 0x08, 0xd6, 0x8d,
+	0x39, 0x6f, 0xaf, 0x27, 0x32, 0x14, 0x25, 0xd4, 0xab, 0x18, 0x7f, 0x01, 0xe8, 0x58, 0xa6, 0x0d,
+	0xe8, 0xb8, 0xc6, 0x90, 0xe3, 0x69, 0x36, 0xa5, 0xd2, 0x62, 0x37, 0x56, 0x04, 0x72, 0xcb, 0x4c,
+	0x68, 0x7b, 0xdd, 0x58, 0x11, 0xc8, 0x9d, 0xa7, 0x8b, 0x54, 0xa1, 0xed, 0xc5, 0x8a, 0x88, 0xbe,
+	0x80, 0xa1, 0xb6, 0xaa, 0x4a, 0xae, 0x35, 0x9a, 0x09, 0x96, 0x52, 0xd3, 0x6d, 0xd6, 0x31, 0xb7,
+	0xea, 0x20, 0x89, 0xcd, 0x76, 0xf4, 0x9b, 0x03, 0xfd, 0xa7, 0x27, 0x74, 0x95, 0xd2, 0x5b, 0xe7,
+	0x7d, 0xcb, 0xce, 0xfb, 0x4d, 0x70, 0xa7, 0x0b, 0x33, 0x84, 0xe1, 0x52, 0x8d, 0xac, 0x8c, 0xcc,
+	0xe7, 0x74, 0xae, 0x03, 0xb8, 0xa2, 0xa5, 0xe7, 0xd2, 0x05, 0xc5, 0x24, 0x51, 0x19, 0x66, 0x48,
+	0x95, 0x25, 0x8c, 0x92, 0x85, 0x7c, 0x5b, 0x37, 0xd6, 0x54, 0xf4, 0xa7, 0x03, 0x03, 0x65, 0x61,
+	0x3d, 0x2e, 0x9d, 0x49, 0xff, 0x2d, 0xe8, 0xe4, 0xa5, 0x28, 0x4a, 0x61, 0x52, 0x4c, 0x51, 0x68,
+	0x32, 0x65, 0x2c, 0x37, 0x51, 0xae, 0x08, 0x34, 0x30, 0x29, 0x19, 0xa9, 0x02, 0xdd, 0x8d, 0x2b,
+	0xda, 0x4a, 0x56, 0xaf, 0x91, 0xac, 0xa7, 0x12, 0xb3, 0x73, 0x26, 0x31, 0xf1, 0x60, 0x3a, 0xcb,
+	0x48, 0x15, 0xf1, 0x8a, 0x42, 0x73, 0x0f, 0xd3, 0x2c, 0xd1, 0x93, 0xb6, 0x5c, 0x23, 0x0a, 0x05,
+	0x61, 0x22, 0x25, 0x73, 0x39, 0x67, 0x77, 0x63, 0x43, 0x46, 0x6f, 0x5a, 0x00, 0xcf, 0x18, 0xc9,
+	0x74, 0x32, 0xaf, 0x43, 0x2b, 0x4d, 0x74, 0x2a, 0xb7, 0xd2, 0xe4, 0xdc, 0x98, 0xaa, 0x27, 0x3e,
+	0xb7, 0x31, 0xf1, 0x61, 0x21, 0x20, 0x33, 0xac, 0x21, 0xc8, 0x95, 0x6b, 0xff, 0x3a, 0xf4, 0x30,
+	0xd1, 0x29, 0xe7, 0xd4, 0xa4, 0x72, 0xcd, 0x40, 0xb4, 0x54, 0x53, 0xea, 0x28, 0x07, 0x4b, 0x42,
+	0xa5, 0x14, 0xe1, 0x79, 0x66, 0x1e, 0xa6, 0x28, 0x1c, 0xd2, 0x99, 0x8a, 0x1a, 0x9a, 0x8c, 0x27,
+	0xe6, 0x53, 0xa2, 0x5f, 0xf1, 0xf6, 0x96, 0x08, 0x1a, 0x29, 0x0a, 0x96, 0x1f, 0x29, 0x09, 0xfd,
+	0x4d, 0x61, 0x58, 0x7b, 0x4b, 0x04, 0x62, 0xca, 0x28, 0x11, 0x34, 0x91, 0x9f, 0x13, 0x6e, 0x6c,
+	0xc8, 0x86, 0x8f, 0xfa, 0xa7, 0x7c, 0xd4, 0x18, 0xe6, 0x06, 0xa7, 0x86, 0xb9, 0xbf, 0x1d, 0x18,
+	0x48, 0x04, 0xff, 0x4d, 0x81, 0x51, 0xb8, 0xbb, 0x67, 0x70, 0x6f, 0x9f, 0x8b, 0xbb, 0x77, 0x2e,
+	0xee, 0x9d, 0x8b, 0x70, 0x5f, 0xbb, 0x10, 0xf7, 0xae, 0x8d, 0xbb, 0x8d, 0x40, 0xef, 0x6c, 0x94,
+	0x6a, 0x9f, 0x80, 0xed, 0x93, 0xe8, 0x73, 0x18, 0xea, 0xb7, 0xeb, 0x64, 0x79, 0x1f, 0x8d, 0x24,
+	0x99, 0x68, 0x14, 0x82, 0x3a, 0xc0, 0x62, 0xbd, 0x7b, 0xef, 0x0f, 0x0f, 0x46, 0xfb, 0x94, 0x1d,
+	0x51, 0x86, 0x03, 0xe5, 0xbe, 0xfa, 0xae, 0xf5, 0x6f, 0x43, 0xfb, 0x9b, 0x9c, 0x24, 0xfe, 0x48,
+	0x8e, 0x27, 0xf6, 0xd7, 0x5b, 0xe8, 0xdb, 0x2c, 0x5d, 0x1e, 0xaf, 0xf8, 0xbb, 0xe0, 0xc9, 0x99,
+	0xd6, 0xdf, 0xb4, 0xc6, 0x5b, 0x75, 0x60, 0x74, 0x66, 0xe0, 0x8d, 0xae, 0xf8, 0x77, 0xa1, 0xa3,
+	0xbe, 0x3c, 0xd4, 0x15, 0x8d, 0x4f, 0x99, 0xd0, 0xb7, 0x59, 0xd5, 0x91, 0x5b, 0xd0, 0xc6, 0x91,
+	0xd3, 0xdf, 0x90, 0xbb, 0xf5, 0x9c, 0x1a, 0x6e, 0xd6, 0x0c, 0xdb, 0x1e, 0xf9, 0xd5, 0xa0, 0xec,
+	0xb1, 0x3f, 0x36, 0xc2, 0x91, 0xc5, 0xa9, 0xe4, 0x6f, 0x43, 0xe7, 0xd1, 0x74, 0x4a, 0x39, 0x57,
+	0x07, 0xec, 0xa9, 0x34, 0x1c, 0x59, 0x1c, 0xdb, 0x1a, 0xf9, 0xf1, 0xb7, 0x51, 0x0f, 0x70, 0x96,
+	0x35, 0xf6, 0x44, 0x17, 0x5d, 0xf1, 0x3f, 0x85, 0x35, 0x3d, 0x06, 0xf9, 0xf2, 0x6d, 0xcd, 0xb9,
+	0x2a, 0xfc, 0x4f, 0x83, 0x57, 0x9d, 0xba, 0x07, 0x9e, 0x9c, 0x33, 0x94, 0x49, 0xf6, 0x24, 0x14,
+	0x8e, 0x2c, 0x8e, 0x91, 0xdf, 0x71, 0xee, 0x38, 0x88, 0xab, 0x6a, 0x6b, 0x0a, 0xd7, 0x46, 0xc7,
+	0x0d, 0x7d, 0x9b, 0x65, 0x43, 0x25, 0x1b, 0x84, 0xba, 0xc6, 0xee, 0x72, 0xe1, 0xc8, 0xe2, 0x54,
+	0xf2, 0x1f, 0x43, 0x1b, 0xcb, 0xb2, 0x7a, 0xb9, 0xd5, 0x42, 0xc2, 0xcd, 0x9a, 0x61, 0x84, 0xef,
+	0x38, 0xa8, 0x5e, 0x86, 0x9d, 0x52, 0x6f, 0x27, 0x68, 0x38, 0xb2, 0x38, 0x95, 0xfa, 0xcf, 0x60,
+	0xa8, 0xf7, 0x6d, 0x87, 0xbc, 0xf5, 0xdc, 0xa4, 0x23, 0xff, 0x8b, 0xf9, 0xe4, 0x9f, 0x01, 0x00,
+	0x46, 0x6c, 0xf8, 0x98, 0x9c, 0x11, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ServerNodeServiceClient is the client API for ServerNodeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ServerNodeServiceClient interface {
+	Load(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpResponse, error)
+	Cache(ctx context.Context, in *CacheRequest, opts ...grpc.CallOption) (*CacheResponse, error)
+	Access(ctx context.Context, in *BasicRequest, opts ...grpc.CallOption) (*BasicResponse, error)
+	User(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	HostKey(ctx context.Context, in *HostKeyRequest, opts ...grpc.CallOption) (*HostKeyResponse, error)
+	Proxy(ctx context.Context, opts ...grpc.CallOption) (ServerNodeService_ProxyClient, error)
+	Report(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportResponse, error)
+	Audit(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditResponse, error)
+	Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (ServerNodeService_ExecClient, error)
+	Grant(ctx context.Context, in *GrantRequest, opts ...grpc.CallOption) (*GrantResponse, error)
+	RequestAccess(ctx context.Context, in *GrantRequest, opts ...grpc.CallOption) (*GrantResponse, error)
+}
+
+type serverNodeServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewServerNodeServiceClient(cc *grpc.ClientConn) ServerNodeServiceClient {
+	return &serverNodeServiceClient{cc}
+}
+
+func (c *serverNodeServiceClient) Load(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Load", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (*DumpResponse, error) {
+	out := new(DumpResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Dump", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Cache(ctx context.Context, in *CacheRequest, opts ...grpc.CallOption) (*CacheResponse, error) {
+	out := new(CacheResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Cache", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Access(ctx context.Context, in *BasicRequest, opts ...grpc.CallOption) (*BasicResponse, error) {
+	out := new(BasicResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Access", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) User(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/User", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) HostKey(ctx context.Context, in *HostKeyRequest, opts ...grpc.CallOption) (*HostKeyResponse, error) {
+	out := new(HostKeyResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/HostKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Proxy(ctx context.Context, opts ...grpc.CallOption) (ServerNodeService_ProxyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ServerNodeService_serviceDesc.Streams[0], "/pb.ServerNodeService/Proxy", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serverNodeServiceProxyClient{stream}
+	return x, nil
+}
+
+type ServerNodeService_ProxyClient interface {
+	Send(*ProxyRequest) error
+	Recv() (*ProxyResponse, error)
+	grpc.ClientStream
+}
+
+type serverNodeServiceProxyClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverNodeServiceProxyClient) Send(m *ProxyRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serverNodeServiceProxyClient) Recv() (*ProxyResponse, error) {
+	m := new(ProxyResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serverNodeServiceClient) Report(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Report", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Audit(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditResponse, error) {
+	out := new(AuditResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Audit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (ServerNodeService_ExecClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ServerNodeService_serviceDesc.Streams[1], "/pb.ServerNodeService/Exec", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serverNodeServiceExecClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServerNodeService_ExecClient interface {
+	Recv() (*ExecResponse, error)
+	grpc.ClientStream
+}
+
+type serverNodeServiceExecClient struct {
+	grpc.ClientStream
+}
+
+func (x *serverNodeServiceExecClient) Recv() (*ExecResponse, error) {
+	m := new(ExecResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serverNodeServiceClient) Grant(ctx context.Context, in *GrantRequest, opts ...grpc.CallOption) (*GrantResponse, error) {
+	out := new(GrantResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/Grant", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverNodeServiceClient) RequestAccess(ctx context.Context, in *GrantRequest, opts ...grpc.CallOption) (*GrantResponse, error) {
+	out := new(GrantResponse)
+	err := c.cc.Invoke(ctx, "/pb.ServerNodeService/RequestAccess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ServerNodeServiceServer is the server API for ServerNodeService service.
+type ServerNodeServiceServer interface {
+	Load(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	Dump(context.Context, *DumpRequest) (*DumpResponse, error)
+	Cache(context.Context, *CacheRequest) (*CacheResponse, error)
+	Access(context.Context, *BasicRequest) (*BasicResponse, error)
+	User(context.Context, *UserRequest) (*UserResponse, error)
+	HostKey(context.Context, *HostKeyRequest) (*HostKeyResponse, error)
+	Proxy(ServerNodeService_ProxyServer) error
+	Report(context.Context, *ReportRequest) (*ReportResponse, error)
+	Audit(context.Context, *AuditRequest) (*AuditResponse, error)
+	Exec(*ExecRequest, ServerNodeService_ExecServer) error
+	Grant(context.Context, *GrantRequest) (*GrantResponse, error)
+	RequestAccess(context.Context, *GrantRequest) (*GrantResponse, error)
+}
+
+// UnimplementedServerNodeServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedServerNodeServiceServer struct {
+}
+
+func (*UnimplementedServerNodeServiceServer) Load(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Load not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Dump(ctx context.Context, req *DumpRequest) (*DumpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Dump not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Cache(ctx context.Context, req *CacheRequest) (*CacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cache not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Access(ctx context.Context, req *BasicRequest) (*BasicResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Access not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) User(ctx context.Context, req *UserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method User not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) HostKey(ctx context.Context, req *HostKeyRequest) (*HostKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HostKey not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Proxy(srv ServerNodeService_ProxyServer) error {
+	return status.Errorf(codes.Unimplemented, "method Proxy not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Report(ctx context.Context, req *ReportRequest) (*ReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Report not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Audit(ctx context.Context, req *AuditRequest) (*AuditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Audit not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Exec(req *ExecRequest, srv ServerNodeService_ExecServer) error {
+	return status.Errorf(codes.Unimplemented, "method Exec not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) Grant(ctx context.Context, req *GrantRequest) (*GrantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Grant not implemented")
+}
+func (*UnimplementedServerNodeServiceServer) RequestAccess(ctx context.Context, req *GrantRequest) (*GrantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestAccess not implemented")
+}
+
+func RegisterServerNodeServiceServer(s *grpc.Server, srv ServerNodeServiceServer) {
+	s.RegisterService(&_ServerNodeService_serviceDesc, srv)
+}
+
+func _ServerNodeService_Load_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).Load(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ServerNodeService/Load",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerNodeServiceServer).Load(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerNodeService_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
 		Server:     srv,
 		FullMethod: "/pb.ServerNodeService/Query",
 	}
@@ -1132,6 +2427,143 @@ func _ServerNodeService_User_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServerNodeService_HostKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HostKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).HostKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ServerNodeService/HostKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerNodeServiceServer).HostKey(ctx, req.(*HostKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerNodeService_Proxy_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServerNodeServiceServer).Proxy(&serverNodeServiceProxyServer{stream})
+}
+
+type ServerNodeService_ProxyServer interface {
+	Send(*ProxyResponse) error
+	Recv() (*ProxyRequest, error)
+	grpc.ServerStream
+}
+
+type serverNodeServiceProxyServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverNodeServiceProxyServer) Send(m *ProxyResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serverNodeServiceProxyServer) Recv() (*ProxyRequest, error) {
+	m := new(ProxyRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ServerNodeService_Report_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).Report(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ServerNodeService/Report",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerNodeServiceServer).Report(ctx, req.(*ReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerNodeService_Audit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).Audit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ServerNodeService/Audit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerNodeServiceServer).Audit(ctx, req.(*AuditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerNodeService_Exec_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServerNodeServiceServer).Exec(m, &serverNodeServiceExecServer{stream})
+}
+
+type ServerNodeService_ExecServer interface {
+	Send(*ExecResponse) error
+	grpc.ServerStream
+}
+
+type serverNodeServiceExecServer struct {
+	grpc.ServerStream
+}
+
+func (x *serverNodeServiceExecServer) Send(m *ExecResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ServerNodeService_Grant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).Grant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ServerNodeService/Grant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerNodeServiceServer).Grant(ctx, req.(*GrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerNodeService_RequestAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerNodeServiceServer).RequestAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ServerNodeService/RequestAccess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerNodeServiceServer).RequestAccess(ctx, req.(*GrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ServerNodeService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.ServerNodeService",
 	HandlerType: (*ServerNodeServiceServer)(nil),
@@ -1164,7 +2596,39 @@ var _ServerNodeService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "User",
 			Handler:    _ServerNodeService_User_Handler,
 		},
+		{
+			MethodName: "HostKey",
+			Handler:    _ServerNodeService_HostKey_Handler,
+		},
+		{
+			MethodName: "Report",
+			Handler:    _ServerNodeService_Report_Handler,
+		},
+		{
+			MethodName: "Audit",
+			Handler:    _ServerNodeService_Audit_Handler,
+		},
+		{
+			MethodName: "Grant",
+			Handler:    _ServerNodeService_Grant_Handler,
+		},
+		{
+			MethodName: "RequestAccess",
+			Handler:    _ServerNodeService_RequestAccess_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Proxy",
+			Handler:       _ServerNodeService_Proxy_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Exec",
+			Handler:       _ServerNodeService_Exec_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }
@@ -0,0 +1,274 @@
+package picker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"meta"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+	CanceledErr = errors.New("picker canceled")
+)
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+
+	// rows above the node list,the query and the match count
+	headerRows = 2
+)
+
+// Match reports whether every space separated term of query appears in text
+// in order,not necessarily next to each other. Case is ignored.
+func Match(text, query string) bool {
+	text = strings.ToLower(text)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		rest := text
+		for _, r := range term {
+			i := strings.IndexRune(rest, r)
+			if i < 0 {
+				return false
+			}
+			rest = rest[i+utf8.RuneLen(r):]
+		}
+	}
+	return true
+}
+
+func nodeText(node *meta.Node) string {
+	return node.Ip + " " + node.Tag + " " + node.GroupName
+}
+
+// row is a line of the list,a group header when node is nil.
+type row struct {
+	group string
+	node  *meta.Node
+	index int //position among the matched nodes
+}
+
+// Picker filters nodes as the query is typed. Nodes are expected in group
+// order,as cache.OrderNode returns them.
+type Picker struct {
+	nodes    []*meta.Node
+	query    []rune
+	matched  []*meta.Node
+	selected int
+	offset   int //first row shown
+}
+
+func New(nodes []*meta.Node) *Picker {
+	p := &Picker{nodes: nodes}
+	p.filter()
+	return p
+}
+
+func (p *Picker) filter() {
+	p.matched = make([]*meta.Node, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		if Match(nodeText(node), string(p.query)) {
+			p.matched = append(p.matched, node)
+		}
+	}
+	p.selected, p.offset = 0, 0
+}
+
+func (p *Picker) rows() []row {
+	rows := make([]row, 0, len(p.matched)*2)
+	for i, node := range p.matched {
+		if i == 0 || node.GroupName != p.matched[i-1].GroupName {
+			rows = append(rows, row{group: node.GroupName})
+		}
+		rows = append(rows, row{group: node.GroupName, node: node, index: i})
+	}
+	return rows
+}
+
+func (p *Picker) move(delta int) {
+	if len(p.matched) == 0 {
+		return
+	}
+	p.selected = (p.selected + delta + len(p.matched)) % len(p.matched)
+}
+
+// handle applies the keys in b and returns the chosen node once Enter is hit.
+func (p *Picker) handle(b []byte) (*meta.Node, error) {
+	for len(b) > 0 {
+		if b[0] == keyEscape {
+			if len(b) == 1 {
+				return nil, CanceledErr
+			}
+			// arrow keys,ESC [ A or ESC O A,other sequences are ignored
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				switch b[2] {
+				case 'A':
+					p.move(-1)
+				case 'B':
+					p.move(1)
+				}
+				b = b[3:]
+				continue
+			}
+			b = b[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		switch r {
+		case keyCtrlC, keyCtrlD:
+			return nil, CanceledErr
+		case keyEnter, '\n':
+			if len(p.matched) > 0 {
+				return p.matched[p.selected], nil
+			}
+		case keyCtrlP:
+			p.move(-1)
+		case keyCtrlN, '\t':
+			p.move(1)
+		case keyBackspace, keyDelete:
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case keyCtrlU:
+			p.query = p.query[:0]
+			p.filter()
+		case keyCtrlW:
+			trimmed := strings.TrimRightFunc(string(p.query), unicode.IsSpace)
+			if i := strings.LastIndexFunc(trimmed, unicode.IsSpace); i >= 0 {
+				p.query = []rune(trimmed[:i+1])
+			} else {
+				p.query = p.query[:0]
+			}
+			p.filter()
+		default:
+			if unicode.IsPrint(r) {
+				p.query = append(p.query, r)
+				p.filter()
+			}
+		}
+	}
+	return nil, nil
+}
+
+// render draws the picker into height rows of width columns. The terminal is
+// in raw mode,so lines end with \r\n.
+func (p *Picker) render(w io.Writer, width, height int) {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&buf, "> %s\r\n", string(p.query))
+	fmt.Fprintf(&buf, "\x1b[2m  %d/%d nodes,enter to login,esc to quit\x1b[0m\r\n", len(p.matched), len(p.nodes))
+	rows := p.rows()
+	visible := height - headerRows
+	if visible < 1 {
+		visible = 1
+	}
+	selectedRow := 0
+	for i, r := range rows {
+		if r.node != nil && r.index == p.selected {
+			selectedRow = i
+			break
+		}
+	}
+	// keep the group header of the first matched node in view
+	if selectedRow == 1 {
+		selectedRow = 0
+	}
+	if selectedRow < p.offset {
+		p.offset = selectedRow
+	} else if selectedRow >= p.offset+visible {
+		p.offset = selectedRow - visible + 1
+	}
+	for i := p.offset; i < len(rows) && i < p.offset+visible; i++ {
+		r := rows[i]
+		var line string
+		if r.node == nil {
+			line = fmt.Sprintf("\x1b[1m%s\x1b[0m", truncate(r.group, width))
+		} else {
+			text := truncate(fmt.Sprintf("  %-16s %s", r.node.Ip, r.node.Tag), width)
+			if r.index == p.selected {
+				text = "\x1b[7m" + text + "\x1b[0m"
+			}
+			line = text
+		}
+		buf.WriteString(line)
+		if i < p.offset+visible-1 && i < len(rows)-1 {
+			buf.WriteString("\r\n")
+		}
+	}
+	// put the cursor back behind the query
+	fmt.Fprintf(&buf, "\x1b[1;%dH", 3+len(p.query))
+	w.Write(buf.Bytes())
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// Run reads keys from in and redraws out until a node is chosen. size
+// reports the terminal size before every redraw.
+func (p *Picker) Run(in io.Reader, out io.Writer, size func() (int, int)) (*meta.Node, error) {
+	buf := make([]byte, 64)
+	for {
+		width, height := size()
+		p.render(out, width, height)
+		n, err := in.Read(buf)
+		if n > 0 {
+			node, herr := p.handle(buf[:n])
+			if node != nil || herr != nil {
+				return node, herr
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Pick lets the user choose one of nodes on the terminal behind stdin and
+// stdout,drawn on the alternate screen so the shell output is left as it was.
+func Pick(nodes []*meta.Node) (*meta.Node, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("no node to pick")
+	}
+	fd := int(os.Stdin.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	defer terminal.Restore(fd, state)
+	os.Stdout.WriteString("\x1b[?1049h")
+	defer os.Stdout.WriteString("\x1b[?1049l")
+	size := func() (int, int) {
+		width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return 80, 24
+		}
+		return width, height
+	}
+	return New(nodes).Run(os.Stdin, os.Stdout, size)
+}
+
+// IsTerminal reports whether both stdin and stdout are terminals,the picker
+// needs them and falls back to a table otherwise.
+func IsTerminal() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
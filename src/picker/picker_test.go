@@ -0,0 +1,68 @@
+package picker
+
+import (
+	"bytes"
+	"meta"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func testNodes() []*meta.Node {
+	return []*meta.Node{
+		{Ip: "10.0.0.1", Tag: "d1", GroupName: "db"},
+		{Ip: "10.0.0.2", Tag: "d2", GroupName: "db"},
+		{Ip: "10.0.1.1", Tag: "w1", GroupName: "web"},
+		{Ip: "10.0.1.2", Tag: "w2", GroupName: "web"},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		query string
+		ok    bool
+	}{
+		{"", true},
+		{"web", true},
+		{"WEB w2", true},
+		{"1012", true},
+		{"db", false},
+		{"w3", false},
+	}
+	for _, c := range cases {
+		if Match("10.0.1.2 w2 web", c.query) != c.ok {
+			t.Errorf("match %q expect %v", c.query, c.ok)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	size := func() (int, int) { return 80, 24 }
+	cases := []struct {
+		input string
+		ip    string
+	}{
+		{"\r", "10.0.0.1"},
+		{"web\r", "10.0.1.1"},
+		{"web\x1b[B\r", "10.0.1.2"},
+		{"\x1b[A\r", "10.0.1.2"},
+		{"w2\x10\r", "10.0.1.2"},
+		{"xx\x7f\x7fd2\r", "10.0.0.2"},
+		{"zz\x15\x0e\r", "10.0.0.2"},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		node, err := New(testNodes()).Run(strings.NewReader(c.input), &out, size)
+		if err != nil || node == nil || node.Ip != c.ip {
+			t.Errorf("input %q expect %s,got %v %v", c.input, c.ip, node, err)
+		}
+	}
+	if _, err := New(testNodes()).Run(strings.NewReader("web\x03"), &bytes.Buffer{}, size); err != CanceledErr {
+		t.Errorf("ctrl-c expect canceled,got %v", err)
+	}
+	var out bytes.Buffer
+	New(testNodes()).Run(iotest.OneByteReader(strings.NewReader("web\x03")), &out, size)
+	if !strings.Contains(out.String(), "2/4 nodes") {
+		t.Errorf("render without match count: %q", out.String())
+	}
+}
@@ -0,0 +1,200 @@
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	Version         = 2
+	FileExt         = ".cast"
+	eventOutput     = "o"
+	eventResize     = "r"
+	eventMarker     = "m"
+	fileTimeFormat  = "20060102-150405"
+	maxReplayLine   = 4 * 1024 * 1024
+	defaultIdleTime = 2 * time.Second
+)
+
+var (
+	InvalidFormatErr = errors.New("not an asciicast v2 recording")
+)
+
+// Header is the first line of an asciicast v2 file. User and Node are extra
+// keys players ignore,End is only known once the session is over and is
+// recorded as the "end" marker instead.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	User      string            `json:"user,omitempty"`
+	Node      string            `json:"node,omitempty"`
+}
+
+// Recorder writes the output of one terminal session as asciicast v2 events.
+type Recorder struct {
+	path    string
+	file    *os.File
+	start   time.Time
+	lock    sync.Mutex
+	pending []byte //incomplete utf-8 sequence from the last write
+}
+
+// NewRecorder creates dir/<node>_<user>_<time>.cast,readable only by the owner.
+func NewRecorder(dir, user, node string, width, height int) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	name := fmt.Sprintf("%s_%s_%s%s", node, strings.Replace(user, "/", "_", -1), start.Format(fileTimeFormat), FileExt)
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	term := os.Getenv("TERM")
+	if len(term) == 0 {
+		term = "xterm-256color"
+	}
+	header := &Header{
+		Version:   Version,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     fmt.Sprintf("%s on %s", user, node),
+		Env:       map[string]string{"TERM": term},
+		User:      user,
+		Node:      node,
+	}
+	b, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err = file.Write(append(b, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Recorder{
+		path:  path,
+		file:  file,
+		start: start,
+	}, nil
+}
+
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+func (r *Recorder) event(kind, data string) error {
+	b, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), kind, data})
+	if err != nil {
+		return err
+	}
+	_, err = r.file.Write(append(b, '\n'))
+	return err
+}
+
+// Write records b as output,holding back a trailing partial utf-8 sequence
+// so multi-byte characters split across reads are not mangled.
+func (r *Recorder) Write(b []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	data := append(r.pending, b...)
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	r.pending = append([]byte{}, data[end:]...)
+	if end == 0 {
+		return len(b), nil
+	}
+	if err := r.event(eventOutput, string(data[:end])); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (r *Recorder) Resize(width, height int) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.event(eventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Close flushes what is left and marks the end time of the session.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.pending) > 0 {
+		r.event(eventOutput, string(r.pending))
+		r.pending = nil
+	}
+	r.event(eventMarker, "end "+time.Now().Format(time.RFC3339))
+	return r.file.Close()
+}
+
+// Play writes the output events of an asciicast v2 recording to w with their
+// original timing divided by speed. Pauses longer than maxIdle are shortened,
+// a zero maxIdle uses 2 seconds.
+func Play(in io.Reader, w io.Writer, speed float64, maxIdle time.Duration) (*Header, error) {
+	if speed <= 0 {
+		speed = 1
+	}
+	if maxIdle <= 0 {
+		maxIdle = defaultIdleTime
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxReplayLine)
+	if !scanner.Scan() {
+		return nil, InvalidFormatErr
+	}
+	header := &Header{}
+	if err := json.Unmarshal(scanner.Bytes(), header); err != nil || header.Version != Version {
+		return nil, InvalidFormatErr
+	}
+	var last float64
+	for scanner.Scan() {
+		var event []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 {
+			return header, InvalidFormatErr
+		}
+		at, ok := event[0].(float64)
+		kind, _ := event[1].(string)
+		data, _ := event[2].(string)
+		if !ok {
+			return header, InvalidFormatErr
+		}
+		if kind != eventOutput {
+			continue
+		}
+		wait := time.Duration((at - last) / speed * float64(time.Second))
+		if wait > maxIdle {
+			wait = maxIdle
+		}
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		last = at
+		if _, err := io.WriteString(w, data); err != nil {
+			return header, err
+		}
+	}
+	return header, scanner.Err()
+}
@@ -0,0 +1,51 @@
+package record
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRecordAndPlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rec, err := NewRecorder(dir, "root@10.0.0.2", "10.0.0.1", 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	word := []byte("hello 世界\r\n")
+	rec.Write(word[:8]) //split inside a multi-byte character
+	rec.Resize(100, 30)
+	rec.Write(word[8:])
+	if err = rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(rec.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var out bytes.Buffer
+	header, err := Play(file, &out, 100, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.User != "root@10.0.0.2" || header.Node != "10.0.0.1" || header.Width != 80 {
+		t.Fatal("unexpected header ", header)
+	}
+	if out.String() != string(word) {
+		t.Fatalf("replay %q,expect %q", out.String(), string(word))
+	}
+}
+
+func TestPlayInvalid(t *testing.T) {
+	if _, err := Play(bytes.NewBufferString("{\"version\":1}\n"), ioutil.Discard, 1, 0); err != InvalidFormatErr {
+		t.Fatal("expect invalid format,got ", err)
+	}
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	log "logging"
+	"meta"
+	"path"
+	"pb"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	auditResultOk = "ok"
+	maxAuditLimit = 10000
+)
+
+// auditedMethods are the unary rpcs written to the audit log,frequent
+// read-only checks such as Access and Cache are left out.
+var auditedMethods = map[string]bool{
+	"Load":    true,
+	"Delete":  true,
+	"Query":   true,
+	"Dump":    true,
+	"User":    true,
+	"HostKey": true,
+	"Audit":   true,
+	"Grant":   true,
+	// RequestAccess is audited too,so requests are visible before approval
+	"RequestAccess": true,
+}
+
+func auditResult(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return auditResultOk
+}
+
+func responseDetail(responses []*pb.Response) string {
+	items := make([]string, 0, len(responses))
+	for _, response := range responses {
+		items = append(items, fmt.Sprintf("%s:%s", response.Addr, response.Msg))
+	}
+	return strings.Join(items, ",")
+}
+
+// auditRequest returns the claimed caller,the targets and extra detail of an
+// audited rpc. resp is nil when the call failed.
+func auditRequest(req, resp interface{}) (string, []string, string) {
+	switch in := req.(type) {
+	case *pb.UpdateRequest:
+		targets := make([]string, 0, len(in.NodeMetas))
+		for _, n := range in.NodeMetas {
+			targets = append(targets, n.Host)
+		}
+		var detail string
+		if out, ok := resp.(*pb.UpdateResponse); ok && out != nil {
+			detail = responseDetail(out.Response)
+		}
+		return in.AuthorityUser, targets, detail
+	case *pb.DeleteRequest:
+		targets := make([]string, 0)
+		var detail string
+		if out, ok := resp.(*pb.DeleteResponse); ok && out != nil {
+			for _, response := range out.Response {
+				targets = append(targets, response.Addr)
+			}
+			detail = responseDetail(out.Response)
+		}
+		return in.Username, targets, "groups " + strings.Join(in.Groups, ",") + " " + detail
+	case *pb.QueryRequest:
+		targets := make([]string, 0)
+		if out, ok := resp.(*pb.QueryResponse); ok && out != nil {
+			for _, n := range out.NodeMetas {
+				targets = append(targets, n.Host)
+			}
+		}
+		return in.Username, targets, "groups " + strings.Join(in.GroupNames, ",")
+	case *pb.DumpRequest:
+		return in.Username, nil, ""
+	case *pb.UserRequest:
+		return in.Username, nil, ""
+	case *pb.HostKeyRequest:
+		var detail string
+		if out, ok := resp.(*pb.HostKeyResponse); ok && out != nil {
+			detail = responseDetail(out.Response)
+		}
+		return in.Username, in.Hosts, detail
+	case *pb.GrantRequest:
+		action := strings.ToLower(in.Action)
+		if len(action) == 0 {
+			action = GrantActionList
+		}
+		items := []string{action}
+		if out, ok := resp.(*pb.GrantResponse); ok && out != nil && action != GrantActionList && len(out.Grants) == 1 {
+			items = append(items, fmt.Sprintf("id=%d", out.Grants[0].Id), "user="+out.Grants[0].User)
+		} else if in.Id > 0 {
+			items = append(items, fmt.Sprintf("id=%d", in.Id))
+		}
+		for _, field := range []struct {
+			name  string
+			value []string
+		}{{"groups", in.Groups}, {"tags", in.Tags}, {"roles", in.Roles}} {
+			if len(field.value) > 0 {
+				items = append(items, field.name+"="+strings.Join(field.value, ","))
+			}
+		}
+		if in.Duration > 0 {
+			items = append(items, fmt.Sprintf("duration=%ds", in.Duration))
+		}
+		if len(in.Reason) > 0 {
+			items = append(items, "reason="+in.Reason)
+		}
+		return in.Username, in.Addresses, strings.Join(items, " ")
+	case *pb.AuditRequest:
+		return in.Username, nil, fmt.Sprintf("user=%s node=%s since=%d until=%d", in.User, in.Node, in.Since, in.Until)
+	}
+	return "", nil, ""
+}
+
+// audit appends an entry for the caller behind ctx. The name is resolved the
+// same way the permission checks do,so it is the certificate name under mtls.
+func (s *Server) audit(ctx context.Context, claimed, action string, targets []string, result, detail string) {
+	name, err := s.callerName(ctx, claimed)
+	if err != nil {
+		name = claimed
+	}
+	entry := &meta.AuditEntry{
+		Time:    time.Now(),
+		User:    name,
+		Action:  action,
+		Targets: targets,
+		Result:  result,
+		Detail:  strings.TrimSpace(detail),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.Addr = p.Addr.String()
+	}
+	if err = meta.AppendAudit(entry); err != nil {
+		log.Error("audit ", action, " of ", name, ":", err)
+	}
+}
+
+func (s *Server) auditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if method := path.Base(info.FullMethod); auditedMethods[method] {
+		claimed, targets, detail := auditRequest(req, resp)
+		s.audit(ctx, claimed, strings.ToLower(method), targets, auditResult(err), detail)
+	}
+	return resp, err
+}
+
+// Report records an action a client ran on the nodes itself.
+func (s *Server) Report(ctx context.Context, in *pb.ReportRequest) (*pb.ReportResponse, error) {
+	_, b, _ := s.checkAccessPermission(ctx, in.Username)
+	if !b {
+		return nil, errors.New("Permission denied")
+	}
+	if len(in.Action) == 0 {
+		return nil, errors.New("empty action")
+	}
+	s.audit(ctx, in.Username, "client:"+in.Action, in.Targets, in.Result, in.Detail)
+	return &pb.ReportResponse{}, nil
+}
+
+// Audit queries the audit log,users other than super users only see their own entries.
+func (s *Server) Audit(ctx context.Context, in *pb.AuditRequest) (*pb.AuditResponse, error) {
+	username, b, _ := s.checkAccessPermission(ctx, in.Username)
+	if !b {
+		return nil, errors.New("Permission denied")
+	}
+	filter := &meta.AuditFilter{
+		User:  in.User,
+		Node:  in.Node,
+		Limit: int(in.Limit),
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if _, ok := s.checkSuperPermission(ctx, in.Username); !ok {
+		filter.User = username
+	}
+	if in.Since > 0 {
+		filter.Since = time.Unix(in.Since, 0)
+	}
+	if in.Until > 0 {
+		filter.Until = time.Unix(in.Until, 0)
+	}
+	entries, err := meta.QueryAudit(filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.AuditResponse{
+		Entries: make([]*pb.AuditEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &pb.AuditEntry{
+			Time:    e.Time.Unix(),
+			User:    e.User,
+			Addr:    e.Addr,
+			Action:  e.Action,
+			Targets: e.Targets,
+			Result:  e.Result,
+			Detail:  e.Detail,
+		})
+	}
+	return resp, nil
+}
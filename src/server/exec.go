@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	log "logging"
+	"meta"
+	"pb"
+	"ssh"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExecTimeout bounds commands run without a timeout,so a hung node
+	// can not hold a server worker forever.
+	defaultExecTimeout = 10 * time.Minute
+)
+
+// Exec runs a command on nodes from the server after checking the command
+// policies of the caller,so restricted users never need node credentials.
+// Results are streamed back as the hosts finish.
+func (s *Server) Exec(in *pb.ExecRequest, stream pb.ServerNodeService_ExecServer) error {
+	ctx := stream.Context()
+	username, b := s.checkPermission(ctx, in.Username, PermRun)
+	if !b {
+		s.audit(ctx, in.Username, "run", in.Hosts, "Permission denied", in.Cmd)
+		return errors.New("Permission denied")
+	}
+	cmd := strings.TrimSpace(in.Cmd)
+	if len(cmd) == 0 {
+		return errors.New("empty command")
+	}
+	timeout := time.Duration(in.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+
+	nodes := make([]*meta.Node, 0, len(in.Hosts))
+	// denied hosts are sent after unlocking,a slow client must not hold the
+	// lock every other rpc takes
+	denied := make([]*pb.ExecResponse, 0)
+	s.mutex.RLock()
+	for _, host := range in.Hosts {
+		node := meta.FetchNode(host)
+		var err error
+		if !s.canAccessNode(username, node) {
+			err = errors.New("Permission denied")
+		} else {
+			err = s.checkCommand(username, node, cmd)
+		}
+		if err != nil {
+			denied = append(denied, &pb.ExecResponse{Host: host, Error: err.Error(), ExitStatus: -1, Kind: string(ssh.KindDenied)})
+			continue
+		}
+		node.HostKey = meta.FetchHostKey(node.Ip)
+		nodes = append(nodes, node)
+	}
+	s.mutex.RUnlock()
+	failed := len(denied)
+	for _, resp := range denied {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	// the lines of many hosts and the results share the stream
+	var sendMutex sync.Mutex
+	send := func(resp *pb.ExecResponse) {
+		sendMutex.Lock()
+		defer sendMutex.Unlock()
+		// keep draining so every result is counted in the audit entry
+		if err == nil {
+			if err = stream.Send(resp); err != nil {
+				log.Warn("exec ", username, ":", err)
+			}
+		}
+	}
+	if len(nodes) > 0 {
+		executor := ssh.NewExecutor(int(in.Parallel), timeout)
+		var results <-chan *ssh.Result
+		if in.Stream {
+			results = executor.Stream(ctx, nodes, cmd, func(node *meta.Node, stderr bool, line []byte) {
+				// the newline keeps an empty line of stderr apart from stdout
+				line = append(append(make([]byte, 0, len(line)+1), line...), '\n')
+				resp := &pb.ExecResponse{Host: node.Ip, Partial: true}
+				if stderr {
+					resp.Stderr = line
+				} else {
+					resp.Output = line
+				}
+				send(resp)
+			})
+		} else {
+			// a client gone away cancels the context and so the commands
+			results = executor.ExecuteContext(ctx, nodes, cmd)
+		}
+		for res := range results {
+			resp := &pb.ExecResponse{
+				Host:       res.Node.Ip,
+				Output:     res.Stdout,
+				Stderr:     res.Stderr,
+				ExitStatus: int32(res.ExitStatus),
+				Signal:     res.Signal,
+				Kind:       string(res.Kind),
+				Duration:   int64(res.Duration / time.Millisecond),
+			}
+			if res.Err != nil {
+				failed++
+				resp.Error = res.Err.Error()
+			}
+			send(resp)
+		}
+	}
+	s.audit(ctx, in.Username, "run", in.Hosts, fmt.Sprintf("%d ok,%d failed", len(in.Hosts)-failed, failed), cmd)
+	return err
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	log "logging"
+	"meta"
+	"pb"
+	"strings"
+	"time"
+	"utils"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	DefaultGrantDuration = 4 * time.Hour
+	MaxGrantDuration     = 7 * 24 * time.Hour
+	// pendingGrantTTL drops requests nobody approved
+	pendingGrantTTL    = 24 * time.Hour
+	grantCheckInterval = time.Minute
+)
+
+const (
+	GrantActionAdd     = "add"
+	GrantActionApprove = "approve"
+	GrantActionRevoke  = "revoke"
+	GrantActionList    = "list"
+)
+
+func grantEntry(g *meta.Grant) *pb.GrantEntry {
+	entry := &pb.GrantEntry{
+		Id:          int64(g.Id),
+		User:        g.User,
+		Groups:      g.Groups,
+		Tags:        g.Tags,
+		Addresses:   g.Addresses,
+		Roles:       g.Roles,
+		Reason:      g.Reason,
+		RequestedBy: g.RequestedBy,
+		ApprovedBy:  g.ApprovedBy,
+		Created:     g.Created.Unix(),
+		Duration:    int64(g.Duration / time.Second),
+	}
+	if g.Approved() {
+		entry.ExpireAt = g.ExpireAt.Unix()
+	}
+	return entry
+}
+
+// newGrant checks the request and returns an unapproved grant for user.
+func (s *Server) newGrant(in *pb.GrantRequest, user, requestedBy string) (*meta.Grant, error) {
+	if len(user) == 0 {
+		return nil, errors.New("grant needs a user")
+	}
+	if len(in.Groups) == 0 && len(in.Tags) == 0 && len(in.Addresses) == 0 && len(in.Roles) == 0 {
+		return nil, errors.New("grant needs groups,tags,addresses or roles")
+	}
+	for _, addr := range in.Addresses {
+		if err := utils.ValidHostPattern(addr); err != nil {
+			return nil, fmt.Errorf("address %s: %v", addr, err)
+		}
+	}
+	s.mutex.RLock()
+	roles := s.roles
+	s.mutex.RUnlock()
+	for _, role := range in.Roles {
+		if _, ok := builtinRoles[role]; ok {
+			continue
+		}
+		if _, ok := roles[role]; !ok {
+			return nil, fmt.Errorf("unknown role %s", role)
+		}
+	}
+	duration := time.Duration(in.Duration) * time.Second
+	if duration == 0 {
+		duration = DefaultGrantDuration
+	}
+	if duration < 0 || duration > MaxGrantDuration {
+		return nil, fmt.Errorf("duration must be positive and at most %s", MaxGrantDuration)
+	}
+	return &meta.Grant{
+		User:        user,
+		Groups:      in.Groups,
+		Tags:        in.Tags,
+		Addresses:   in.Addresses,
+		Roles:       in.Roles,
+		Reason:      in.Reason,
+		RequestedBy: requestedBy,
+		Created:     time.Now(),
+		Duration:    duration,
+	}, nil
+}
+
+// refreshGrants rebuilds the privileges with the current grants and makes the
+// clients of users refetch their cache.
+func (s *Server) refreshGrants(users ...string) error {
+	if err := initServerAuthorityConfig(s.authorityConfigPath, false, s); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, user := range users {
+		if info, ok := s.userPrivilege[user]; ok {
+			info.IsNeedUpateCache = true
+			log.Info(user, " need to update cache:", true)
+		}
+	}
+	return nil
+}
+
+func (s *Server) listGrants(username string, all bool) (*pb.GrantResponse, error) {
+	grants, err := meta.FetchGrants()
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.GrantResponse{
+		Grants: make([]*pb.GrantEntry, 0, len(grants)),
+	}
+	for _, g := range grants {
+		if all || g.User == username || g.RequestedBy == username {
+			resp.Grants = append(resp.Grants, grantEntry(g))
+		}
+	}
+	return resp, nil
+}
+
+// Grant lists grants for everyone,super users also add,approve and revoke them.
+func (s *Server) Grant(ctx context.Context, in *pb.GrantRequest) (*pb.GrantResponse, error) {
+	username, b, _ := s.checkAccessPermission(ctx, in.Username)
+	if !b {
+		return nil, errors.New("Permission denied")
+	}
+	_, isSuper := s.checkSuperPermission(ctx, in.Username)
+	action := strings.ToLower(in.Action)
+	if len(action) == 0 || action == GrantActionList {
+		return s.listGrants(username, isSuper)
+	}
+	if !isSuper {
+		return nil, errors.New("Permission denied")
+	}
+	var g *meta.Grant
+	var err error
+	switch action {
+	case GrantActionAdd:
+		user := in.User
+		if len(user) == 0 {
+			user = username
+		}
+		if g, err = s.newGrant(in, user, username); err != nil {
+			return nil, err
+		}
+		g.Approve(username, time.Now())
+		if err = meta.AddGrant(g); err != nil {
+			return nil, err
+		}
+	case GrantActionApprove:
+		if g, err = meta.FetchGrant(uint64(in.Id)); err != nil {
+			return nil, err
+		}
+		if g.Approved() {
+			return nil, fmt.Errorf("grant %d is already approved", g.Id)
+		}
+		g.Approve(username, time.Now())
+		if err = meta.UpdateGrant(g); err != nil {
+			return nil, err
+		}
+	case GrantActionRevoke:
+		if g, err = meta.FetchGrant(uint64(in.Id)); err != nil {
+			return nil, err
+		}
+		if err = meta.DeleteGrant(g.Id); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown grant action %s", in.Action)
+	}
+	log.Info("grant ", g.Id, " of ", g.User, " ", action, " by ", username)
+	if g.Approved() {
+		if err = s.refreshGrants(g.User); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.GrantResponse{Grants: []*pb.GrantEntry{grantEntry(g)}}, nil
+}
+
+// RequestAccess asks for a grant for the caller,it counts once a super user
+// approves it. Callers need not be in the authority config yet.
+func (s *Server) RequestAccess(ctx context.Context, in *pb.GrantRequest) (*pb.GrantResponse, error) {
+	username, err := s.callerName(ctx, in.Username)
+	if err != nil || len(username) == 0 {
+		return nil, errors.New("Permission denied")
+	}
+	in.Action = GrantActionAdd //the only action,also what the audit entry shows
+	g, err := s.newGrant(in, username, username)
+	if err != nil {
+		return nil, err
+	}
+	if err = meta.AddGrant(g); err != nil {
+		return nil, err
+	}
+	log.Info("grant ", g.Id, " requested by ", username)
+	return &pb.GrantResponse{Grants: []*pb.GrantEntry{grantEntry(g)}}, nil
+}
+
+// expireGrants drops expired grants and requests nobody approved in time,and
+// takes the expired grants away from their users.
+func (s *Server) expireGrants() {
+	grants, err := meta.FetchGrants()
+	if err != nil {
+		log.Error("expire grants:", err)
+		return
+	}
+	now := time.Now()
+	users := make([]string, 0)
+	for _, g := range grants {
+		expired := g.Approved() && !now.Before(g.ExpireAt)
+		stale := !g.Approved() && now.Sub(g.Created) > pendingGrantTTL
+		if !expired && !stale {
+			continue
+		}
+		if err = meta.DeleteGrant(g.Id); err != nil {
+			log.Error("expire grant ", g.Id, ":", err)
+			continue
+		}
+		log.Info("grant ", g.Id, " of ", g.User, " expired")
+		if expired {
+			users = append(users, g.User)
+		}
+	}
+	if len(users) == 0 {
+		return
+	}
+	if err = s.refreshGrants(users...); err != nil {
+		log.Error("refresh grants:", err)
+	}
+}
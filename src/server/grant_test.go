@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"db"
+	"meta"
+	"os"
+	"pb"
+	"testing"
+	"time"
+)
+
+func TestNewGrant(t *testing.T) {
+	s := newRbacServer(t, rbacConfig)
+	cases := []struct {
+		req *pb.GrantRequest
+		ok  bool
+	}{
+		{&pb.GrantRequest{Groups: []string{"prod"}}, true},
+		{&pb.GrantRequest{Roles: []string{"operator"}, Duration: 3600}, true},
+		{&pb.GrantRequest{}, false},
+		{&pb.GrantRequest{Roles: []string{"missing"}}, false},
+		{&pb.GrantRequest{Addresses: []string{"10.0.0.0/40"}}, false},
+		{&pb.GrantRequest{Groups: []string{"prod"}, Duration: int64(MaxGrantDuration/time.Second) + 1}, false},
+		{&pb.GrantRequest{Groups: []string{"prod"}, Duration: -1}, false},
+	}
+	for _, c := range cases {
+		g, err := s.newGrant(c.req, "eve", "root")
+		if (err == nil) != c.ok {
+			t.Errorf("%v expect %v,got %v", c.req, c.ok, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		now := time.Now()
+		if g.Active(now) {
+			t.Error("grant active before approval")
+		}
+		g.Approve("root", now)
+		if !g.Active(now) || g.Active(now.Add(g.Duration)) {
+			t.Errorf("grant %v active window wrong", c.req)
+		}
+	}
+}
+
+func TestGrantOnlyUser(t *testing.T) {
+	if _, err := os.Stat(db.DefaultStorageFile); os.IsNotExist(err) {
+		defer os.Remove(db.DefaultStorageFile)
+	}
+	if err := db.InitDBHandler(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		db.DBHandler.Close()
+		db.DBHandler = nil
+	}()
+	g := &meta.Grant{User: "frank", Groups: []string{"prod"}, Duration: time.Hour, Created: time.Now()}
+	g.Approve("root", time.Now())
+	if err := meta.AddGrant(g); err != nil {
+		t.Fatal(err)
+	}
+	defer meta.DeleteGrant(g.Id)
+	s := newRbacServer(t, rbacConfig)
+	info, ok := s.userPrivilege["frank"]
+	if !ok || !info.Groups["prod"] {
+		t.Fatal("grant not applied to frank")
+	}
+	if len(info.Roles) > 0 || s.hasPermission("frank", PermLogin) {
+		t.Fatal("grant only user got roles ", info.Roles)
+	}
+	if !s.hasPermission("alice", PermLogin) {
+		t.Fatal("config user lost the normal role")
+	}
+}
+
+// grants reload the privileges every minute while rpcs check them,run with
+// -race.
+func TestRefreshGrantsConcurrent(t *testing.T) {
+	s := newRbacServer(t, rbacConfig)
+	s.authorityConfigPath = writeConfig(t, rbacConfig)
+	defer os.Remove(s.authorityConfigPath)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if err := s.refreshGrants("alice"); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	node := &meta.Node{Ip: "10.0.0.2"}
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if _, ok := s.checkPermission(context.Background(), "alice", PermLogin); !ok {
+			t.Fatal("alice lost login during a refresh")
+		}
+		if _, ok := s.checkSuperPermission(context.Background(), "root"); !ok {
+			t.Fatal("root lost super during a refresh")
+		}
+		s.mutex.RLock()
+		ok := s.canAccessNode("alice", node)
+		s.mutex.RUnlock()
+		if !ok {
+			t.Fatal("alice lost 10.0.0.2 during a refresh")
+		}
+	}
+}
+
+// the handlers that change nodes must check the caller before they take the
+// write lock,checkPermission reads the privileges under the same lock.
+func TestLoadDeniedUnlocked(t *testing.T) {
+	s := newRbacServer(t, rbacConfig)
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Load(context.Background(), &pb.UpdateRequest{AuthorityUser: "carol"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "Permission denied" {
+			t.Fatal("expect permission denied,got ", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("load deadlocked on the server lock")
+	}
+}
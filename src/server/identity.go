@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	log "logging"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var (
+	errNoClientCert = errors.New("client certificate required")
+)
+
+// callerName returns who is calling. When client certificates are verified
+// the identity is the common name of the certificate, so the user name the
+// client claims in the request is only compared and logged. Without mutual
+// tls the claimed name is all the server has.
+func (s *Server) callerName(ctx context.Context, claimed string) (string, error) {
+	if !s.verifyClient {
+		return claimed, nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", errNoClientCert
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", errNoClientCert
+	}
+	name := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	if len(claimed) > 0 && !strings.EqualFold(name, claimed) {
+		log.Warn("caller claims ", claimed, " but certificate is ", name, ",use certificate")
+	}
+	return name, nil
+}
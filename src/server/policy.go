@@ -0,0 +1,312 @@
+package server
+
+import (
+	"fmt"
+	"meta"
+	"regexp"
+	"strings"
+)
+
+const (
+	regexPatternPrefix = "re:"
+	shellMetaChars     = ";&|`$<>(){}\n\\"
+)
+
+// DefaultReadOnlyCommands are allowed by read-only policies when the config
+// has no read_only_commands of its own.
+var DefaultReadOnlyCommands = []string{
+	"ls", "cat", "head", "tail", "grep", "wc", "stat", "df", "du", "free",
+	"uptime", "uname", "whoami", "id", "ps", "top -b", "w", "who", "last",
+	"lsblk", "netstat", "ss", "ip addr show", "ip route show",
+	"systemctl status", "journalctl", "re:^hostname$", "re:^date$",
+}
+
+// CommandPolicy restricts the commands its users may run on its nodes. A
+// pattern is a command with optional leading arguments,matching it with any
+// further arguments,or a regular expression after "re:".
+// Empty Users and UserGroups mean every user,empty Groups and Tags every node.
+type CommandPolicy struct {
+	Name       string   `json:"name"`
+	Users      []string `json:"users,omitempty"`
+	UserGroups []string `json:"user_groups,omitempty"`
+	Groups     []string `json:"groups,omitempty"` //node groups
+	Tags       []string `json:"tags,omitempty"`   //node tags
+	Allow      []string `json:"allow,omitempty"`
+	Deny       []string `json:"deny,omitempty"`
+	ReadOnly   bool     `json:"read_only,omitempty"` //also allow read_only_commands
+}
+
+type commandPattern struct {
+	prefix string
+	re     *regexp.Regexp
+}
+
+func newCommandPattern(pattern string) (*commandPattern, error) {
+	if strings.HasPrefix(pattern, regexPatternPrefix) {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, regexPatternPrefix))
+		if err != nil {
+			return nil, err
+		}
+		return &commandPattern{re: re}, nil
+	}
+	return &commandPattern{prefix: strings.TrimSpace(pattern)}, nil
+}
+
+func (p *commandPattern) match(cmd string) bool {
+	if p.re != nil {
+		return p.re.MatchString(cmd)
+	}
+	return cmd == p.prefix || strings.HasPrefix(cmd, p.prefix+" ")
+}
+
+func compilePatterns(patterns []string) ([]*commandPattern, error) {
+	compiled := make([]*commandPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := newCommandPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %v", pattern, err)
+		}
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
+func matchPatterns(patterns []*commandPattern, cmd string) bool {
+	for _, p := range patterns {
+		if p.match(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+type commandPolicy struct {
+	*CommandPolicy
+	users      map[string]bool
+	userGroups map[string]bool
+	groups     map[string]bool
+	tags       map[string]bool
+	allow      []*commandPattern
+	deny       []*commandPattern
+}
+
+func toSet(items []string, lower bool) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range items {
+		if lower {
+			item = strings.ToLower(item)
+		}
+		set[item] = true
+	}
+	return set
+}
+
+// commandPolicies is the compiled form of the policies of an authority config.
+type commandPolicies struct {
+	policies []*commandPolicy
+	readOnly []*commandPattern
+}
+
+func newCommandPolicies(conf *AuthorityConfig) (*commandPolicies, error) {
+	readOnly := conf.ReadOnlyCommands
+	if len(readOnly) == 0 {
+		readOnly = DefaultReadOnlyCommands
+	}
+	compiled, err := compilePatterns(readOnly)
+	if err != nil {
+		return nil, fmt.Errorf("read_only_commands: %v", err)
+	}
+	cp := &commandPolicies{
+		policies: make([]*commandPolicy, 0, len(conf.Policies)),
+		readOnly: compiled,
+	}
+	for index := range conf.Policies {
+		policy := &conf.Policies[index]
+		p := &commandPolicy{
+			CommandPolicy: policy,
+			users:         toSet(policy.Users, false),
+			userGroups:    toSet(policy.UserGroups, false),
+			groups:        toSet(policy.Groups, true),
+			tags:          toSet(policy.Tags, true),
+		}
+		if p.allow, err = compilePatterns(policy.Allow); err != nil {
+			return nil, fmt.Errorf("policy %s allow: %v", policy.Name, err)
+		}
+		if p.deny, err = compilePatterns(policy.Deny); err != nil {
+			return nil, fmt.Errorf("policy %s deny: %v", policy.Name, err)
+		}
+		cp.policies = append(cp.policies, p)
+	}
+	return cp, nil
+}
+
+func (p *commandPolicy) appliesTo(username string, info *UserInfo) bool {
+	if len(p.users) == 0 && len(p.userGroups) == 0 {
+		return true
+	}
+	if p.users[username] {
+		return true
+	}
+	for group := range info.UserGroups {
+		if p.userGroups[group] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *commandPolicy) covers(node *meta.Node) bool {
+	if len(p.groups) == 0 && len(p.tags) == 0 {
+		return true
+	}
+	return p.groups[strings.ToLower(node.GroupName)] || p.tags[strings.ToLower(node.Tag)]
+}
+
+func (p *commandPolicy) restricts() bool {
+	return len(p.allow) > 0 || p.ReadOnly
+}
+
+// commandWrappers run the command given as their arguments,mapped to the
+// letters of their options that take a value.
+var commandWrappers = map[string]string{
+	"sudo": "CDghprtUu", "env": "CSu", "nice": "n", "exec": "a",
+	"command": "", "builtin": "", "nohup": "", "time": "", "eval": "",
+}
+
+// commandSegments splits a shell command on ; & | new lines,command
+// substitution,subshells and braces,so deny patterns also catch commands
+// chained after or hidden inside an allowed one.
+func commandSegments(cmd string) []string {
+	fields := strings.FieldsFunc(cmd, func(r rune) bool {
+		return strings.ContainsRune(";&|\n`(){}", r)
+	})
+	segments := make([]string, 0, len(fields))
+	for _, segment := range fields {
+		if segment = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(segment), "$")); len(segment) > 0 {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// baseCommand drops leading variable assignments and wrappers such as sudo
+// from segment and the directory of the program,so /sbin/reboot and
+// sudo reboot both read reboot.
+func baseCommand(segment string) string {
+	words := strings.Fields(segment)
+	for len(words) > 0 {
+		word := strings.Trim(words[0], `"'`)
+		if strings.Contains(word, "=") && !strings.HasPrefix(word, "=") {
+			words = words[1:]
+			continue
+		}
+		if i := strings.LastIndex(word, "/"); i >= 0 {
+			word = word[i+1:]
+		}
+		if valued, ok := commandWrappers[word]; ok {
+			words = words[1:]
+			// options of the wrapper,such as sudo -u root or nice -n 10
+			for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+				option := words[0]
+				words = words[1:]
+				if len(option) == 2 && strings.Contains(valued, option[1:]) && len(words) > 0 {
+					words = words[1:]
+				}
+			}
+			continue
+		}
+		words[0] = word
+		break
+	}
+	return strings.Join(words, " ")
+}
+
+// denied reports whether a deny pattern matches cmd or any command in it.
+func denied(patterns []*commandPattern, cmd string) bool {
+	if matchPatterns(patterns, cmd) {
+		return true
+	}
+	for _, segment := range commandSegments(cmd) {
+		if matchPatterns(patterns, segment) || matchPatterns(patterns, baseCommand(segment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// restricted reports whether any policy applies to username,such users only
+// run commands through the server. The caller holds s.mutex.
+func (s *Server) restricted(username string) bool {
+	info, ok := s.userPrivilege[username]
+	if !ok || info.Super || s.policies == nil {
+		return false
+	}
+	for _, p := range s.policies.policies {
+		if p.appliesTo(username, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkShell returns why username may not open an interactive shell on node,
+// or nil. A shell runs any command,so no policy that limits the commands on
+// node may apply to the user. The caller holds s.mutex.
+func (s *Server) checkShell(username string, node *meta.Node) error {
+	info, ok := s.userPrivilege[username]
+	if !ok {
+		return fmt.Errorf("unknown user %s", username)
+	}
+	if info.Super || s.policies == nil {
+		return nil
+	}
+	for _, p := range s.policies.policies {
+		if p.appliesTo(username, info) && p.covers(node) && p.restricts() {
+			return fmt.Errorf("login denied by policy %s,use vsh run", p.Name)
+		}
+	}
+	return nil
+}
+
+// checkCommand returns why username may not run cmd on node,or nil. Super
+// users are not bound by policies. A deny pattern of any policy rejects the
+// command,and when a policy limits the commands one of those policies must
+// allow it. Deny patterns are a guard against mistakes only,a shell has too
+// many ways to hide a command; restricting users needs allow patterns,which
+// also forbid shell operators. The caller holds s.mutex.
+func (s *Server) checkCommand(username string, node *meta.Node, cmd string) error {
+	info, ok := s.userPrivilege[username]
+	if !ok {
+		return fmt.Errorf("unknown user %s", username)
+	}
+	if info.Super || s.policies == nil {
+		return nil
+	}
+	cmd = strings.TrimSpace(cmd)
+	restricted, allowed := false, false
+	for _, p := range s.policies.policies {
+		if !p.appliesTo(username, info) || !p.covers(node) {
+			continue
+		}
+		if denied(p.deny, cmd) {
+			return fmt.Errorf("denied by policy %s", p.Name)
+		}
+		if !p.restricts() {
+			continue
+		}
+		restricted = true
+		if matchPatterns(p.allow, cmd) || (p.ReadOnly && matchPatterns(s.policies.readOnly, cmd)) {
+			allowed = true
+		}
+	}
+	if !restricted {
+		return nil
+	}
+	if strings.ContainsAny(cmd, shellMetaChars) {
+		return fmt.Errorf("shell operators are not allowed by policy")
+	}
+	if !allowed {
+		return fmt.Errorf("command not allowed by policy")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"meta"
+	"os"
+	"testing"
+)
+
+const policyConfig = `{
+  "user_groups": [{"name": "ops", "members": ["bob"], "groups": ["web"]}],
+  "user_ref_nodes": [
+    {"uname": "root", "type": 1},
+    {"uname": "alice", "type": 0},
+    {"uname": "bob", "type": 0},
+    {"uname": "carol", "type": 0}
+  ],
+  "policies": [
+    {"name": "no-reboot", "deny": ["reboot", "re:^shutdown"]},
+    {"name": "ops-web", "user_groups": ["ops"], "groups": ["web"], "allow": ["systemctl restart nginx"], "read_only": true},
+    {"name": "alice-db", "users": ["alice"], "tags": ["db"], "allow": ["re:^psql -c 'select"]}
+  ]
+}`
+
+func TestCheckCommand(t *testing.T) {
+	s := newRbacServer(t, policyConfig)
+	web := &meta.Node{Ip: "10.0.0.1", GroupName: "Web"}
+	db := &meta.Node{Ip: "10.0.0.2", GroupName: "data", Tag: "db"}
+	cases := []struct {
+		user string
+		node *meta.Node
+		cmd  string
+		ok   bool
+	}{
+		{"root", web, "reboot", true},
+		{"carol", web, "rm -rf /tmp/x", true},
+		{"carol", web, "reboot", false},
+		{"carol", web, "uptime; reboot", false},
+		{"carol", web, "shutdown -h now", false},
+		{"carol", web, "echo $(reboot)", false},
+		{"carol", web, "echo `reboot`", false},
+		{"carol", web, "/sbin/reboot", false},
+		{"carol", web, "sudo -n /usr/sbin/shutdown -r now", false},
+		{"carol", web, "FORCE=1 env reboot", false},
+		{"carol", web, "(cd /; reboot)", false},
+		{"carol", web, "cat reboot.log", true},
+		{"bob", web, "systemctl restart nginx", true},
+		{"bob", web, "systemctl restart mysql", false},
+		{"bob", web, "df -h", true},
+		{"bob", web, "wget http://x", false}, //"w" is read-only,not wget
+		{"bob", web, "cat /etc/hosts | sh", false},
+		{"bob", db, "touch /tmp/x", true}, //ops-web does not cover db nodes
+		{"alice", db, "psql -c 'select 1'", true},
+		{"alice", db, "psql -c 'drop table t'", false},
+		{"alice", web, "psql -c 'drop table t'", true},
+		{"nobody", web, "ls", false},
+	}
+	for _, c := range cases {
+		if err := s.checkCommand(c.user, c.node, c.cmd); (err == nil) != c.ok {
+			t.Errorf("%s on %s %q expect %v,got %v", c.user, c.node.Ip, c.cmd, c.ok, err)
+		}
+	}
+	for user, restricted := range map[string]bool{"root": false, "alice": true, "bob": true, "carol": true} {
+		if s.restricted(user) != restricted {
+			t.Errorf("%s restricted expect %v", user, restricted)
+		}
+	}
+}
+
+func TestBaseCommand(t *testing.T) {
+	for segment, expect := range map[string]string{
+		"reboot":                         "reboot",
+		"/sbin/reboot -f":                "reboot -f",
+		"sudo -u root /sbin/reboot":      "reboot",
+		"LANG=C nice env A=1 ls -l /tmp": "ls -l /tmp",
+		"'/bin/rm' -rf x":                "rm -rf x",
+	} {
+		if got := baseCommand(segment); got != expect {
+			t.Errorf("baseCommand(%q) = %q, expect %q", segment, got, expect)
+		}
+	}
+}
+
+func TestCheckShell(t *testing.T) {
+	s := newRbacServer(t, policyConfig)
+	web := &meta.Node{Ip: "10.0.0.1", GroupName: "Web"}
+	db := &meta.Node{Ip: "10.0.0.2", GroupName: "data", Tag: "db"}
+	cases := []struct {
+		user string
+		node *meta.Node
+		ok   bool
+	}{
+		{"root", web, true},
+		{"carol", web, true}, //deny rules alone do not limit a shell
+		{"bob", web, false},
+		{"bob", db, true},
+		{"alice", db, false},
+		{"alice", web, true},
+		{"nobody", web, false},
+	}
+	for _, c := range cases {
+		if err := s.checkShell(c.user, c.node); (err == nil) != c.ok {
+			t.Errorf("%s shell on %s expect %v,got %v", c.user, c.node.Ip, c.ok, err)
+		}
+	}
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	path := writeConfig(t, `{"user_ref_nodes": [{"uname": "root", "type": 1}],
+  "policies": [{"name": "bad", "allow": ["re:("]}]}`)
+	defer os.Remove(path)
+	s := newRbacServer(t, `{"user_ref_nodes": [{"uname": "root", "type": 1}]}`)
+	if err := initServerAuthorityConfig(path, false, s); err == nil {
+		t.Error("expect invalid regex to fail the config")
+	}
+}
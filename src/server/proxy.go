@@ -0,0 +1,174 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	log "logging"
+	"meta"
+	"pb"
+	"record"
+	"ssh"
+	"sync"
+	"time"
+
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	proxyDialTimeout = 10 * time.Second
+	defaultTermType  = "xterm-256color"
+)
+
+// proxyOutput forwards the node output to the client,one message per write.
+type proxyOutput struct {
+	stream pb.ServerNodeService_ProxyServer
+	lock   *sync.Mutex
+	stderr bool
+}
+
+func (o *proxyOutput) Write(b []byte) (int, error) {
+	data := make([]byte, len(b))
+	copy(data, b)
+	resp := &pb.ProxyResponse{}
+	if o.stderr {
+		resp.Stderr = data
+	} else {
+		resp.Stdout = data
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if err := o.stream.Send(resp); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Proxy dials the node with the stored credentials and relays a pty between it
+// and the client,so node credentials never leave the server.
+func (s *Server) Proxy(stream pb.ServerNodeService_ProxyServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	targets := []string{req.Host}
+	username, b := s.checkPermission(stream.Context(), req.Username, PermLogin)
+	node := meta.FetchNode(req.Host)
+	s.mutex.RLock()
+	if b = b && s.canAccessNode(username, node); b {
+		err = s.checkShell(username, node)
+	}
+	s.mutex.RUnlock()
+	if !b {
+		s.audit(stream.Context(), req.Username, "login", targets, "Permission denied", "proxy")
+		return errors.New("Permission denied")
+	}
+	if err != nil {
+		s.audit(stream.Context(), req.Username, "login", targets, err.Error(), "proxy")
+		return err
+	}
+	node.HostKey = meta.FetchHostKey(node.Ip)
+
+	lock := &sync.Mutex{}
+	started := false
+	detail := "proxy"
+	// exit ends the session for the client and audits the login when the shell
+	// never started,or the logout with its exit status.
+	exit := func(status int, msg string) error {
+		if !started {
+			s.audit(stream.Context(), req.Username, "login", targets, msg, detail)
+		} else if len(msg) > 0 {
+			s.audit(stream.Context(), req.Username, "logout", targets, msg, detail)
+		} else {
+			s.audit(stream.Context(), req.Username, "logout", targets, fmt.Sprintf("exit status %d", status), detail)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		return stream.Send(&pb.ProxyResponse{
+			Exit:       true,
+			ExitStatus: int32(status),
+			Msg:        msg,
+		})
+	}
+	client, err := ssh.Dial(node, proxyDialTimeout)
+	if err != nil {
+		log.Error("proxy ", username, " to ", node.Ip, ":", err)
+		return exit(-1, err.Error())
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return exit(-1, err.Error())
+	}
+	defer session.Close()
+
+	term := req.Term
+	if len(term) == 0 {
+		term = defaultTermType
+	}
+	modes := cryptossh.TerminalModes{
+		cryptossh.ECHO:          1,
+		cryptossh.TTY_OP_ISPEED: 14400,
+		cryptossh.TTY_OP_OSPEED: 14400,
+	}
+	if err = session.RequestPty(term, int(req.Height), int(req.Width), modes); err != nil {
+		return exit(-1, err.Error())
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return exit(-1, err.Error())
+	}
+	var stdout, stderr io.Writer = &proxyOutput{stream: stream, lock: lock}, &proxyOutput{stream: stream, lock: lock, stderr: true}
+	var rec *record.Recorder
+	if len(s.recordDir) > 0 {
+		if rec, err = record.NewRecorder(s.recordDir, username, node.Ip, int(req.Width), int(req.Height)); err != nil {
+			log.Error("record proxy ", username, " to ", node.Ip, ":", err)
+		} else {
+			defer rec.Close()
+			stdout, stderr = io.MultiWriter(stdout, rec), io.MultiWriter(stderr, rec)
+			log.Info("record proxy ", username, " to ", node.Ip, " in ", rec.Path())
+			detail = "proxy,record " + rec.Path()
+		}
+	}
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if err = session.Shell(); err != nil {
+		return exit(-1, err.Error())
+	}
+	started = true
+	s.audit(stream.Context(), req.Username, "login", targets, auditResultOk, detail)
+	log.Info("proxy ", username, " to ", node.Ip, " start")
+	defer log.Info("proxy ", username, " to ", node.Ip, " exit")
+
+	go func() {
+		defer session.Close()
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF && stream.Context().Err() == nil {
+					log.Warn("proxy ", username, " to ", node.Ip, ":", err)
+				}
+				return
+			}
+			if len(req.Data) > 0 {
+				if _, err = stdin.Write(req.Data); err != nil {
+					return
+				}
+			}
+			if req.Height > 0 && req.Width > 0 {
+				session.WindowChange(int(req.Height), int(req.Width))
+				if rec != nil {
+					rec.Resize(int(req.Width), int(req.Height))
+				}
+			}
+		}
+	}()
+
+	if err = session.Wait(); err != nil {
+		if exitErr, ok := err.(*cryptossh.ExitError); ok {
+			return exit(exitErr.ExitStatus(), "")
+		}
+		return exit(-1, err.Error())
+	}
+	return exit(0, "")
+}
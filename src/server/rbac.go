@@ -0,0 +1,224 @@
+package server
+
+import (
+	"fmt"
+	log "logging"
+	"meta"
+	"sort"
+	"strings"
+	"utils"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	PermLoad      = "load"
+	PermDelete    = "delete"
+	PermDump      = "dump"
+	PermRun       = "run"
+	PermLogin     = "login"
+	PermListUsers = "list-users"
+)
+
+// built-in roles,super also reaches every node and may accept host keys and
+// read the whole audit log.
+const (
+	SuperRole  = "super"
+	NormalRole = "normal"
+	LimitRole  = "limit"
+)
+
+var AllPermissions = []string{PermLoad, PermDelete, PermDump, PermRun, PermLogin, PermListUsers}
+
+var builtinRoles = map[string][]string{
+	SuperRole:  AllPermissions,
+	NormalRole: {PermLoad, PermRun, PermLogin, PermListUsers},
+	LimitRole:  {PermLogin},
+}
+
+// legacyRoles maps the old "type" of a user to a role.
+var legacyRoles = map[int]string{
+	NormalUserType: NormalRole,
+	SuperUserType:  SuperRole,
+	LimitUserType:  LimitRole,
+}
+
+// UserGroup grants roles and nodes to all of its members at once.
+type UserGroup struct {
+	Name      string   `json:"name"`
+	Members   []string `json:"members"`
+	Roles     []string `json:"roles,omitempty"`
+	Groups    []string `json:"groups,omitempty"` //node groups
+	Tags      []string `json:"tags,omitempty"`   //node tags
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+func isPermission(perm string) bool {
+	for _, p := range AllPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks roles and permissions named in the config,so a typo fails
+// the reload instead of silently dropping a grant.
+func (conf *AuthorityConfig) validate() error {
+	for role, perms := range conf.Roles {
+		if _, ok := builtinRoles[role]; ok {
+			return fmt.Errorf("role %s is built-in", role)
+		}
+		for _, perm := range perms {
+			if !isPermission(perm) {
+				return fmt.Errorf("role %s: unknown permission %s", role, perm)
+			}
+		}
+	}
+	checkAddresses := func(owner string, addresses []string) error {
+		for _, addr := range addresses {
+			if err := utils.ValidHostPattern(addr); err != nil {
+				return fmt.Errorf("%s: address %s: %v", owner, addr, err)
+			}
+		}
+		return nil
+	}
+	if err := checkAddresses("pub_nodes", conf.PublicAddress); err != nil {
+		return err
+	}
+	checkRoles := func(owner string, roles []string) error {
+		for _, role := range roles {
+			if _, ok := builtinRoles[role]; ok {
+				continue
+			}
+			if _, ok := conf.Roles[role]; !ok {
+				return fmt.Errorf("%s: unknown role %s", owner, role)
+			}
+		}
+		return nil
+	}
+	for index := 0; index < len(conf.UserRefNodes); index++ {
+		user := &conf.UserRefNodes[index]
+		if user.Type < NormalUserType || user.Type > LimitUserType {
+			log.Warn("user ", user.Name, " has invalid type ", user.Type, ",use normal")
+			user.Type = NormalUserType
+		}
+		if err := checkRoles(user.Name, user.Roles); err != nil {
+			return err
+		}
+		if err := checkAddresses(user.Name, user.Address); err != nil {
+			return err
+		}
+	}
+	for _, group := range conf.UserGroups {
+		if err := checkRoles("user group "+group.Name, group.Roles); err != nil {
+			return err
+		}
+		if err := checkAddresses("user group "+group.Name, group.Addresses); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conf *AuthorityConfig) rolePermissions(role string) []string {
+	if perms, ok := builtinRoles[role]; ok {
+		return perms
+	}
+	return conf.Roles[role]
+}
+
+// grant adds roles and node grants to info.
+func (conf *AuthorityConfig) grant(info *UserInfo, roles, groups, tags []string) {
+	for _, role := range roles {
+		if _, ok := info.roleSet[role]; ok {
+			continue
+		}
+		info.roleSet[role] = 1
+		info.Roles = append(info.Roles, role)
+		if role == SuperRole {
+			info.Super = true
+		}
+		for _, perm := range conf.rolePermissions(role) {
+			info.Permissions[perm] = true
+		}
+	}
+	for _, group := range groups {
+		info.Groups[strings.ToLower(group)] = true
+	}
+	for _, tag := range tags {
+		info.Tags[strings.ToLower(tag)] = true
+	}
+}
+
+func newUserInfo() *UserInfo {
+	return &UserInfo{
+		Roles:       make([]string, 0),
+		Permissions: make(map[string]bool),
+		Groups:      make(map[string]bool),
+		Tags:        make(map[string]bool),
+		UserGroups:  make(map[string]bool),
+		roleSet:     make(map[string]uint8),
+	}
+}
+
+// PermissionList returns the granted permissions in a stable order.
+func (info *UserInfo) PermissionList() []string {
+	perms := make([]string, 0, len(info.Permissions))
+	for perm := range info.Permissions {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// hasPermission reports whether a role of username grants perm,the caller
+// holds s.mutex.
+func (s *Server) hasPermission(username, perm string) bool {
+	info, ok := s.userPrivilege[username]
+	return ok && info.Permissions[perm]
+}
+
+// checkPermission resolves the caller like checkAccessPermission and reports
+// whether one of its roles grants perm.
+func (s *Server) checkPermission(ctx context.Context, claimed, perm string) (string, bool) {
+	name, ok, _ := s.checkAccessPermission(ctx, claimed)
+	if !ok {
+		return name, false
+	}
+	s.mutex.RLock()
+	ok = s.hasPermission(name, perm)
+	s.mutex.RUnlock()
+	if !ok {
+		log.Warn("checkPermission:", name, " lacks ", perm)
+		return name, false
+	}
+	return name, true
+}
+
+// canAccessNode reports whether username may reach node,super users reach
+// every node,others need the address,the node group or the tag granted. It is
+// checked against the node as stored now,so nodes loaded into a granted group
+// or with a granted tag are reachable without touching the config. The caller
+// holds s.mutex.
+func (s *Server) canAccessNode(username string, node *meta.Node) bool {
+	info, ok := s.userPrivilege[username]
+	if !ok || node == nil {
+		return false
+	}
+	if info.Super {
+		return true
+	}
+	for _, pattern := range s.accessNode[username] {
+		if utils.MatchHost(pattern, node.Ip) {
+			return true
+		}
+	}
+	if len(node.GroupName) > 0 && info.Groups[strings.ToLower(node.GroupName)] {
+		return true
+	}
+	if len(node.Tag) > 0 && info.Tags[strings.ToLower(node.Tag)] {
+		return true
+	}
+	return false
+}
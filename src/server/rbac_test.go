@@ -0,0 +1,115 @@
+package server
+
+import (
+	"io/ioutil"
+	"meta"
+	"os"
+	"sync"
+	"testing"
+)
+
+const rbacConfig = `{
+  "pub_nodes": ["10.0.0.1"],
+  "pub_groups": ["shared"],
+  "roles": {"operator": ["run", "login"]},
+  "user_groups": [{"name": "ops", "members": ["bob", "carol"], "roles": ["operator"], "groups": ["Web"]}],
+  "user_ref_nodes": [
+    {"uname": "root", "type": 1},
+    {"uname": "alice", "type": 0, "addresses": ["10.0.0.2", "10.1.0.0/16", "10.2.0.*"], "tags": ["db"]},
+    {"uname": "dave", "type": 2},
+    {"uname": "bob", "type": 0}
+  ]
+}`
+
+func writeConfig(t *testing.T, config string) string {
+	file, err := ioutil.TempFile("", "authority")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(config)
+	file.Close()
+	return file.Name()
+}
+
+func newRbacServer(t *testing.T, config string) *Server {
+	path := writeConfig(t, config)
+	defer os.Remove(path)
+	s := &Server{
+		mutex:         &sync.RWMutex{},
+		accessNode:    make(map[string][]string),
+		userPrivilege: make(map[string]*UserInfo),
+	}
+	if err := initServerAuthorityConfig(path, false, s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRolePermissions(t *testing.T) {
+	s := newRbacServer(t, rbacConfig)
+	cases := []struct {
+		user string
+		perm string
+		ok   bool
+	}{
+		{"root", PermDelete, true},
+		{"alice", PermLoad, true},
+		{"alice", PermDump, false},
+		{"dave", PermLogin, true},
+		{"dave", PermRun, false},
+		{"carol", PermRun, true},
+		{"carol", PermLoad, false},
+		{"bob", PermLoad, true}, //normal from type 0 plus operator from ops
+		{"nobody", PermLogin, false},
+	}
+	for _, c := range cases {
+		if s.hasPermission(c.user, c.perm) != c.ok {
+			t.Errorf("%s %s expect %v", c.user, c.perm, c.ok)
+		}
+	}
+	if s.userPrivilege["root"].Type != SuperUserType || s.userPrivilege["dave"].Type != LimitUserType {
+		t.Error("legacy type not kept")
+	}
+}
+
+func TestCanAccessNode(t *testing.T) {
+	s := newRbacServer(t, rbacConfig)
+	cases := []struct {
+		user string
+		node *meta.Node
+		ok   bool
+	}{
+		{"root", &meta.Node{Ip: "10.0.9.9"}, true},
+		{"alice", &meta.Node{Ip: "10.0.0.1"}, true}, //public
+		{"alice", &meta.Node{Ip: "10.0.0.2"}, true},
+		{"alice", &meta.Node{Ip: "10.0.0.3", Tag: "DB"}, true},
+		{"alice", &meta.Node{Ip: "10.0.0.4", GroupName: "web"}, false},
+		{"carol", &meta.Node{Ip: "10.0.0.4", GroupName: "web"}, true},
+		{"dave", &meta.Node{Ip: "10.0.0.2"}, false},
+		{"dave", &meta.Node{Ip: "10.3.0.1", GroupName: "Shared"}, true},
+		{"alice", &meta.Node{Ip: "10.1.20.3"}, true},
+		{"alice", &meta.Node{Ip: "10.2.0.7"}, true},
+		{"alice", &meta.Node{Ip: "10.2.1.7"}, false},
+	}
+	for _, c := range cases {
+		if s.canAccessNode(c.user, c.node) != c.ok {
+			t.Errorf("%s to %s expect %v", c.user, c.node.Ip, c.ok)
+		}
+	}
+}
+
+func TestInvalidRole(t *testing.T) {
+	for _, config := range []string{
+		`{"roles": {"x": ["reboot"]}, "user_ref_nodes": [{"uname": "a"}]}`,
+		`{"user_ref_nodes": [{"uname": "a", "roles": ["missing"]}]}`,
+		`{"roles": {"super": ["run"]}, "user_ref_nodes": [{"uname": "a"}]}`,
+		`{"pub_nodes": ["10.0.0.0/33"], "user_ref_nodes": [{"uname": "a"}]}`,
+		`{"user_ref_nodes": [{"uname": "a", "addresses": ["10.0.[0.1"]}]}`,
+	} {
+		path := writeConfig(t, config)
+		if _, err := NewAuthorityConfig(path); err == nil {
+			t.Error("expect error for ", config)
+		}
+		os.Remove(path)
+	}
+}
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"crypto/tls"
 	"db"
+	"encode"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +14,7 @@ import (
 	"os"
 	"pb"
 	"sort"
+	"ssh"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,12 +24,14 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
 	defauleDumpFile            = "cluster_dump.json"
 	DefaultAuthorityConfigFile = "template_config.json"
 	defauleCount               = 4
+	DefaultCacheTTL            = 24 * time.Hour
 )
 
 const (
@@ -37,29 +42,54 @@ const (
 
 type UserRefNode struct {
 	Name    string   `json:"uname"`
-	Type    int      `json:"type"`
-	Address []string `json:"addresses"`
+	Type    int      `json:"type"`      //used when roles is empty,0 is normal,1 is super,2 is limit
+	Address []string `json:"addresses"` //ip,cidr or glob
+	Roles   []string `json:"roles,omitempty"`
+	Groups  []string `json:"groups,omitempty"` //node groups
+	Tags    []string `json:"tags,omitempty"`   //node tags
 }
 
 type AuthorityConfig struct {
-	PublicAddress []string      `json:"pub_nodes"`
-	UserRefNodes  []UserRefNode `json:"user_ref_nodes"`
+	PublicAddress []string            `json:"pub_nodes"`            //ip,cidr or glob every user reaches
+	PublicGroups  []string            `json:"pub_groups,omitempty"` //node groups every user reaches
+	PublicTags    []string            `json:"pub_tags,omitempty"`   //node tags every user reaches
+	UserRefNodes  []UserRefNode       `json:"user_ref_nodes"`
+	Roles         map[string][]string `json:"roles,omitempty"` //key is role,value is permissions
+	UserGroups    []UserGroup         `json:"user_groups,omitempty"`
+	Policies      []CommandPolicy     `json:"policies,omitempty"`
+	//commands read-only policies allow,DefaultReadOnlyCommands when empty
+	ReadOnlyCommands []string `json:"read_only_commands,omitempty"`
 }
 
 type UserInfo struct {
 	Type             int
+	Roles            []string
+	Permissions      map[string]bool
+	Super            bool
+	Groups           map[string]bool //granted node groups,lower case
+	Tags             map[string]bool //granted node tags,lower case
+	UserGroups       map[string]bool //user groups the user is a member of
 	IsNeedUpateCache bool
+	roleSet          map[string]uint8
 }
 type Server struct {
 	port                int
 	stop                chan struct{}
 	wg                  *sync.WaitGroup
 	userPrivilege       map[string]*UserInfo //key is Name,value is privileges
-	accessNode          map[string][]string  //key is Name,value is iplist
-	mutex               *sync.Mutex
+	accessNode          map[string][]string  //key is Name,value is ip,cidr or glob
+	mutex               *sync.RWMutex        //guards the privileges,grants reload them
 	dumpMutex           *sync.Mutex
 	timeOut             time.Duration
 	authorityConfigPath string
+	creds               credentials.TransportCredentials
+	verifyClient        bool //identify callers by client certificate
+	cacheTTL            time.Duration
+	proxyOnly           bool   //keep node credentials on the server
+	recordDir           string //where proxied sessions are recorded,empty disables
+	policies            *commandPolicies
+	roles               map[string][]string //custom roles of the authority config
+	cacheCipher         *encode.Cipher      //derives the cache keys
 }
 
 func NewAuthorityConfig(path string) (*AuthorityConfig, error) {
@@ -80,12 +110,9 @@ func NewAuthorityConfig(path string) (*AuthorityConfig, error) {
 		return nil, err
 	}
 
-	for index := 0; index < len(authorityConfig.UserRefNodes); index++ {
-		if authorityConfig.UserRefNodes[index].Type > 1 && authorityConfig.UserRefNodes[index].Type < 0 {
-			authorityConfig.UserRefNodes[index].Type = 0 //default is normal user
-		}
+	if err = authorityConfig.validate(); err != nil {
+		return nil, err
 	}
-
 	return authorityConfig, nil
 }
 func NewServer(port, timeSeconds int, configPath string, wg *sync.WaitGroup) *Server {
@@ -97,12 +124,18 @@ func NewServer(port, timeSeconds int, configPath string, wg *sync.WaitGroup) *Se
 		port:                port,
 		wg:                  wg,
 		stop:                make(chan struct{}),
-		mutex:               &sync.Mutex{},
+		mutex:               &sync.RWMutex{},
 		dumpMutex:           &sync.Mutex{},
 		accessNode:          make(map[string][]string),
 		userPrivilege:       make(map[string]*UserInfo),
 		timeOut:             time.Duration(timeSeconds) * time.Minute,
 		authorityConfigPath: configPath,
+		cacheTTL:            DefaultCacheTTL,
+	}
+	var err error
+	if server.cacheCipher, err = newCacheCipher(); err != nil {
+		log.Error("cache secret:", err)
+		return nil
 	}
 	if err := initServerAuthorityConfig(configPath, false, server); err != nil {
 		log.Error("initServerAuthorityConfig:", err)
@@ -111,6 +144,44 @@ func NewServer(port, timeSeconds int, configPath string, wg *sync.WaitGroup) *Se
 	log.Info("server:", server)
 	return server
 }
+
+// SetCacheTTL sets how long a client may use its cache before asking again.
+func (s *Server) SetCacheTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.cacheTTL = ttl
+	}
+}
+
+// SetProxyOnly stops Query from handing out node credentials,clients then
+// reach nodes through the Proxy stream only.
+func (s *Server) SetProxyOnly(proxyOnly bool) {
+	s.proxyOnly = proxyOnly
+}
+
+// SetRecordDir records every proxied session into dir.
+func (s *Server) SetRecordDir(dir string) {
+	s.recordDir = dir
+}
+
+// EnableTLS serves grpc over TLS with certFile and keyFile. When clientCAFile
+// is not empty every client must present a certificate signed by it.
+func (s *Server) EnableTLS(certFile, keyFile, clientCAFile string) error {
+	conf, pool, err := utils.NewTLSConfig(clientCAFile, certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	if len(conf.Certificates) == 0 {
+		return errors.New("server tls needs cert and key")
+	}
+	if pool != nil {
+		conf.ClientCAs = pool
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+		s.verifyClient = true
+	}
+	s.creds = credentials.NewTLS(conf)
+	log.Info("tls enabled,verify client cert:", pool != nil)
+	return nil
+}
 func initServerAuthorityConfig(configPath string, isDelKeys bool, s *Server) error {
 	authorityConfig, err := NewAuthorityConfig(configPath)
 	if err != nil {
@@ -124,37 +195,94 @@ func initServerAuthorityConfig(configPath string, isDelKeys bool, s *Server) err
 	}
 	log.Info("---authorityConfig:", authorityConfig)
 
+	policies, err := newCommandPolicies(authorityConfig)
+	if err != nil {
+		return err
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	accessNode := make(map[string][]string)
 	userPrivilege := make(map[string]*UserInfo)
-	for _, userRefNode := range authorityConfig.UserRefNodes {
-		address := make(map[string]uint8)
-		if accessNode[userRefNode.Name] == nil {
-			accessNode[userRefNode.Name] = make([]string, 0)
+	addresses := make(map[string]map[string]uint8)
+	register := func(name string) *UserInfo {
+		if info, ok := userPrivilege[name]; ok {
+			return info
+		}
+		info := newUserInfo()
+		if old, ok := s.userPrivilege[name]; ok && old.IsNeedUpateCache {
+			info.IsNeedUpateCache = true
+		} else {
+			info.IsNeedUpateCache = isDelKeys
 		}
+		authorityConfig.grant(info, nil, authorityConfig.PublicGroups, authorityConfig.PublicTags)
+		userPrivilege[name] = info
+		addresses[name] = make(map[string]uint8)
+		accessNode[name] = make([]string, 0)
 		for _, ip := range authorityConfig.PublicAddress {
-			if _, ok := address[ip]; !ok {
-				accessNode[userRefNode.Name] = append(accessNode[userRefNode.Name], ip)
-				address[ip] = 1
+			if _, ok := addresses[name][ip]; !ok {
+				accessNode[name] = append(accessNode[name], ip)
+				addresses[name][ip] = 1
 			}
 		}
-		if userRefNode.Type == 0 {
-			for _, ip := range userRefNode.Address {
-				if _, ok := address[ip]; !ok {
-					accessNode[userRefNode.Name] = append(accessNode[userRefNode.Name], ip)
-					address[ip] = 1
-				}
+		return info
+	}
+	grantAddress := func(name string, ips []string) {
+		for _, ip := range ips {
+			if _, ok := addresses[name][ip]; !ok {
+				accessNode[name] = append(accessNode[name], ip)
+				addresses[name][ip] = 1
 			}
 		}
-		log.Info(userRefNode.Name, " access ", accessNode[userRefNode.Name])
-		if _, ok := userPrivilege[userRefNode.Name]; !ok {
-			userPrivilege[userRefNode.Name] = &UserInfo{
-				Type:             userRefNode.Type,
-				IsNeedUpateCache: isDelKeys,
-			}
-			log.Info("register user :", userRefNode.Name, ",info:", userPrivilege[userRefNode.Name])
+	}
+	// users of the config get the normal role when nothing else is given,
+	// users known from grants only get what was granted
+	configured := make(map[string]bool)
+	for _, userRefNode := range authorityConfig.UserRefNodes {
+		configured[userRefNode.Name] = true
+		info := register(userRefNode.Name)
+		roles := userRefNode.Roles
+		if len(roles) == 0 {
+			roles = []string{legacyRoles[userRefNode.Type]}
+		}
+		authorityConfig.grant(info, roles, userRefNode.Groups, userRefNode.Tags)
+		grantAddress(userRefNode.Name, userRefNode.Address)
+	}
+	for _, group := range authorityConfig.UserGroups {
+		for _, member := range group.Members {
+			configured[member] = true
+			info := register(member)
+			info.UserGroups[group.Name] = true
+			authorityConfig.grant(info, group.Roles, group.Groups, group.Tags)
+			grantAddress(member, group.Addresses)
+		}
+	}
+	// approved temporary grants on top of the config,see grant.go
+	grants, err := meta.FetchGrants()
+	if err != nil && err != db.HandleIsNilErr {
+		log.Error("fetch grants:", err)
+	}
+	now := time.Now()
+	for _, g := range grants {
+		if !g.Active(now) {
+			continue
+		}
+		info := register(g.User)
+		authorityConfig.grant(info, g.Roles, g.Groups, g.Tags)
+		grantAddress(g.User, g.Addresses)
+		log.Info("grant ", g.Id, " to ", g.User, " until ", g.ExpireAt)
+	}
+	for name, info := range userPrivilege {
+		if len(info.Roles) == 0 && configured[name] {
+			authorityConfig.grant(info, []string{NormalRole}, nil, nil)
+		}
+		info.Type = NormalUserType
+		if info.Super {
+			info.Type = SuperUserType
+		} else if len(info.Roles) == 1 && info.Roles[0] == LimitRole {
+			info.Type = LimitUserType
 		}
+		log.Info("register user :", name, ",roles:", info.Roles, ",access ", accessNode[name])
 	}
 	if isDelKeys {
 		for k, _ := range s.accessNode {
@@ -166,6 +294,8 @@ func initServerAuthorityConfig(configPath string, isDelKeys bool, s *Server) err
 	}
 	s.accessNode = accessNode
 	s.userPrivilege = userPrivilege
+	s.policies = policies
+	s.roles = authorityConfig.Roles
 	return nil
 }
 
@@ -186,6 +316,7 @@ func (s *Server) reloadAuthorityConfig(done chan struct{}) {
 				if err := initServerAuthorityConfig(s.authorityConfigPath, true, s); err != nil {
 					log.Error("initServerAuthorityConfig:", err)
 				} else {
+					s.mutex.Lock()
 					for username, userInfo := range s.userPrivilege {
 						userInfo.IsNeedUpateCache = true
 						log.Info(username, " need to update cache:", true)
@@ -193,6 +324,7 @@ func (s *Server) reloadAuthorityConfig(done chan struct{}) {
 					log.Info("reload ", s.authorityConfigPath, " success!")
 					log.Info("authority info:", s.userPrivilege)
 					log.Info("access nodes:", s.accessNode)
+					s.mutex.Unlock()
 				}
 				err = watch.Add(s.authorityConfigPath)
 				if err != nil {
@@ -223,6 +355,17 @@ func (s *Server) CreateTemplateAuthorityConfig() error {
 	conf := &AuthorityConfig{
 		PublicAddress: []string{"127.0.0.1", "127.0.0.2"},
 		UserRefNodes:  make([]UserRefNode, 0),
+		Roles: map[string][]string{
+			"operator": {PermRun, PermLogin, PermListUsers},
+		},
+		UserGroups: []UserGroup{
+			{
+				Name:    "ops",
+				Members: make([]string, 0),
+				Roles:   []string{"operator"},
+				Groups:  []string{"default"},
+			},
+		},
 	}
 	uname, err := utils.GetUserName()
 	if err != nil {
@@ -249,35 +392,49 @@ func (s *Server) CreateTemplateAuthorityConfig() error {
 	return ioutil.WriteFile(DefaultAuthorityConfigFile, bin, os.ModePerm)
 
 }
-func (s *Server) checkAccessPermission(Name string) (bool, int) {
-	defer log.Info("checkAccessPermission:", Name, ",userinfo:", s.userPrivilege[Name])
-	if _, ok := s.userPrivilege[Name]; !ok {
-		return false, -1
+func (s *Server) checkAccessPermission(ctx context.Context, claimed string) (string, bool, int) {
+	name, err := s.callerName(ctx, claimed)
+	if err != nil {
+		log.Warn("checkAccessPermission:", claimed, ":", err)
+		return name, false, -1
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	info, ok := s.userPrivilege[name]
+	log.Info("checkAccessPermission:", name, ",userinfo:", info)
+	if !ok {
+		return name, false, -1
 	}
-	return true, s.userPrivilege[Name].Type
+	return name, true, info.Type
 }
-func (s *Server) checkSuperPermission(Name string) bool {
-	defer log.Info("checkSuperPermission:", Name, ",userinfo:", s.userPrivilege[Name])
-	if _, ok := s.userPrivilege[Name]; !ok {
-		return false
+func (s *Server) checkSuperPermission(ctx context.Context, claimed string) (string, bool) {
+	name, err := s.callerName(ctx, claimed)
+	if err != nil {
+		log.Warn("checkSuperPermission:", claimed, ":", err)
+		return name, false
 	}
-	if s.userPrivilege[Name].Type != 1 {
-		return false
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	info, ok := s.userPrivilege[name]
+	log.Info("checkSuperPermission:", name, ",userinfo:", info)
+	if !ok || !info.Super {
+		return name, false
 	}
-	return true
+	return name, true
 }
 func (s *Server) User(ctx context.Context, in *pb.UserRequest) (*pb.UserResponse, error) {
-	b, _ := s.checkAccessPermission(in.Username)
-	for !b {
+	if _, ok := s.checkPermission(ctx, in.Username, PermListUsers); !ok {
 		return nil, errors.New("Permission denied")
 	}
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	resp := &pb.UserResponse{
 		Response: make(map[string]int32),
+		Roles:    make(map[string]string),
 	}
 	for username, info := range s.userPrivilege {
-			resp.Response[username] = int32(info.Type)
+		resp.Response[username] = int32(info.Type)
+		resp.Roles[username] = strings.Join(info.Roles, ",")
 	}
 	if len(resp.Response) == 0 {
 		return nil, errors.New("empty user")
@@ -288,27 +445,26 @@ func (s *Server) Dump(ctx context.Context, in *pb.DumpRequest) (*pb.DumpResponse
 	resp := &pb.DumpResponse{
 		Response: -1,
 	}
-	if len(in.Username) == 0 {
+	if len(in.Username) == 0 && !s.verifyClient {
 		return nil, errors.New("invalid Name request")
 	}
-	if !s.checkSuperPermission(in.Username) {
+	if _, ok := s.checkPermission(ctx, in.Username, PermDump); !ok {
 		return nil, errors.New("permission denied")
 	}
 	if err := s.internalDump(); err != nil {
 		return nil, err
 	}
 	resp.Response = 0
-	resp.Message = fmt.Sprintf("dump %s", defauleDumpFile, " success on server")
+	resp.Message = fmt.Sprintf("dump %s success on server", defauleDumpFile)
 	return resp, nil
 }
 func (s *Server) Load(ctx context.Context, in *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	if _, ok := s.checkPermission(ctx, in.AuthorityUser, PermLoad); !ok {
+		return nil, errors.New("Permission denied")
+	}
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	var err error
-	b, _ := s.checkAccessPermission(in.AuthorityUser)
-	if !b {
-		return nil, errors.New("Permission denied")
-	}
 	nodes := utils.NewUpdateRequest(in)
 	if nodes == nil || len(nodes) == 0 {
 		return nil, errors.New("invalid nodes")
@@ -318,8 +474,11 @@ func (s *Server) Load(ctx context.Context, in *pb.UpdateRequest) (*pb.UpdateResp
 		Response: make([]*pb.Response, len(nodes)),
 	}
 	log.Info("got nodes len:", len(nodes))
-	var group *meta.Group
-	if group = meta.FetchGroup(); group == nil {
+	group, err := meta.FetchGroup()
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
 		group = &meta.Group{
 			Ref:       make(map[string][]string),
 			Addrs:     make(map[string]uint8),
@@ -333,10 +492,32 @@ func (s *Server) Load(ctx context.Context, in *pb.UpdateRequest) (*pb.UpdateResp
 		resp.Response[index].Addr = node.Ip
 		resp.Response[index].Group = node.GroupName
 
-		if err := utils.ValidSshServer(node); err != nil {
-			log.Error("valid ", node.Ip, ":", err)
+		trustedKey := meta.FetchHostKey(node.Ip)
+		var hostKey string
+		var validErr error
+		if node.AgentOnly() {
+			// the server can not log in with the agent of the client,so the
+			// node is stored unverified and without a recorded host key
+			log.Warn("load ", node.Ip, " unverified,it authenticates with ssh-agent only")
+		} else {
+			node.HostKey = trustedKey
+			hostKey, validErr = utils.ValidSshServer(node)
+			node.HostKey = ""
+		}
+		if validErr != nil {
+			log.Error("valid ", node.Ip, ":", validErr)
 			resp.Response[index].Msg = fmt.Sprint("failed")
+			if _, ok := validErr.(*ssh.HostKeyError); ok {
+				resp.Response[index].Msg = "host key mismatch"
+			}
 		} else {
+			if len(trustedKey) == 0 && len(hostKey) > 0 {
+				if err := meta.UpdateHostKey(node.Ip, hostKey); err != nil {
+					log.Error("record host key of ", node.Ip, ":", err)
+				} else {
+					log.Info("trust host key of ", node.Ip, " on first use")
+				}
+			}
 
 			curNode := meta.FetchNode(node.Ip)
 			if !node.Compare(curNode) {
@@ -390,11 +571,14 @@ func (s *Server) Load(ctx context.Context, in *pb.UpdateRequest) (*pb.UpdateResp
 }
 
 func (s *Server) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	if !s.checkSuperPermission(in.Username) {
+	if _, ok := s.checkPermission(ctx, in.Username, PermDelete); !ok {
 		return nil, errors.New("Permission denied")
 	}
-	var group *meta.Group
-	if group = meta.FetchGroup(); group == nil {
+	group, err := meta.FetchGroup()
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
 		return nil, errors.New("empty group")
 	}
 	log.Info("group info:", group)
@@ -433,6 +617,9 @@ func (s *Server) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteRe
 				response.Msg = "failed"
 			} else {
 				response.Msg = "success"
+				if err = meta.DeleteHostKey(addr); err != nil {
+					log.Error("delete host key of ", addr, ":", err)
+				}
 			}
 			log.Info("delete node:", response)
 			delete(group.Addrs, addr)
@@ -450,36 +637,81 @@ func (s *Server) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.DeleteRe
 }
 
 func (s *Server) Access(ctx context.Context, in *pb.BasicRequest) (*pb.BasicResponse, error) {
-	b, utype := s.checkAccessPermission(in.Username)
+	username, b, utype := s.checkAccessPermission(ctx, in.Username)
 	if !b {
 		return nil, errors.New("Permission denied")
 	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	info, ok := s.userPrivilege[username]
+	if !ok {
+		return nil, errors.New("Permission denied")
+	}
 	return &pb.BasicResponse{
-		Response: int32(utype),
+		Response:    int32(utype),
+		Permissions: info.PermissionList(),
 	}, nil
 
 }
 
 func (s *Server) Cache(ctx context.Context, in *pb.CacheRequest) (*pb.CacheResponse, error) {
-	b, _ := s.checkAccessPermission(in.Username)
+	username, b, _ := s.checkAccessPermission(ctx, in.Username)
 	if !b {
 		return nil, errors.New("Permission denied")
 	}
+	key, expireAt := s.cacheKey(username, time.Now())
 	resp := &pb.CacheResponse{
 		Response: 0,
+		Key:      key,
+		ExpireAt: expireAt,
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	info, ok := s.userPrivilege[username]
+	if !ok {
+		return nil, errors.New("Permission denied")
 	}
-	if s.userPrivilege[in.Username].IsNeedUpateCache {
+	if info.IsNeedUpateCache {
 		resp.Response = 1
 	}
 	return resp, nil
 }
 
+// newCacheCipher derives the cache keys from the master key,or without one
+// from a random secret kept in vsh.db. The built-in key is public,keys derived
+// from it would let anyone compute the cache key of any user.
+func newCacheCipher() (*encode.Cipher, error) {
+	if encode.Configured() {
+		return encode.DefaultCipher(), nil
+	}
+	secret, err := meta.CacheSecret()
+	if err != nil {
+		return nil, err
+	}
+	return encode.NewCipher(secret)
+}
+
+// cacheKey seals the cache of username until the end of the current cache_ttl
+// window. The key changes with every window,so a cache file is of no use with
+// a key handed out before.
+func (s *Server) cacheKey(username string, now time.Time) ([]byte, int64) {
+	ttl := int64(s.cacheTTL / time.Second)
+	if ttl <= 0 {
+		ttl = 1
+	}
+	epoch := now.Unix() / ttl
+	return s.cacheCipher.SubKey(fmt.Sprintf("cache:%s:%d", username, epoch)), (epoch + 1) * ttl
+}
+
 func (s *Server) Query(ctx context.Context, in *pb.QueryRequest) (*pb.QueryResponse, error) {
-	b, _ := s.checkAccessPermission(in.Username)
+	username, b, _ := s.checkAccessPermission(ctx, in.Username)
 	if !b {
 		return nil, errors.New("Permission denied")
 	}
-	groupInfo := meta.FetchGroup()
+	groupInfo, err := meta.FetchGroup()
+	if err != nil {
+		return nil, err
+	}
 	if groupInfo == nil {
 		return nil, errors.New("empty group")
 	}
@@ -511,60 +743,109 @@ func (s *Server) Query(ctx context.Context, in *pb.QueryRequest) (*pb.QueryRespo
 		}
 
 	}
-	accessHosts := make([]string, 0)
-	if s.checkSuperPermission(in.Username) {
-		for ip, _ := range currentHosts {
-			accessHosts = append(accessHosts, ip)
+	accessNodes := make([]*meta.Node, 0)
+	for ip, _ := range currentHosts {
+		node := meta.FetchNode(ip)
+		if node == nil {
+			log.Info("node ", ip, " not exists in cluster")
+			continue
 		}
-	} else {
-		for _, ip := range s.accessNode[in.Username] {
-			if _, ok := currentHosts[ip]; ok {
-				accessHosts = append(accessHosts, ip)
-			} else {
-				log.Info("node ", ip, " not exists in cluster")
-			}
+		if s.canAccessNode(username, node) {
+			accessNodes = append(accessNodes, node)
 		}
 	}
-	if len(accessHosts) == 0 {
+	if len(accessNodes) == 0 {
 		return nil, errors.New("empty nodes")
 	}
-	log.Info("user:", in.Username, " can access:", strings.Join(accessHosts, ","))
-	var nodeCount uint64
-	for _, ip := range accessHosts {
-		node := meta.FetchNode(ip)
-		if node == nil {
-			atomic.AddUint64(&nodeCount, 1)
-			continue
-		}
-		log.Info("ip:", ip, "\ngroupMeta:", res.GroupMetas)
+	// credentials are only handed to users who may run or login directly and
+	// are not bound by command policies,those run commands through Exec
+	withCredential := !s.proxyOnly && !s.restricted(username) &&
+		(s.hasPermission(username, PermRun) || s.hasPermission(username, PermLogin))
+	for _, node := range accessNodes {
+		log.Info("ip:", node.Ip, "\ngroupMeta:", res.GroupMetas)
 		res.GroupMetas[strings.ToLower(node.GroupName)] = res.GroupMetas[strings.ToLower(node.GroupName)] + 1
 
 		nodeMeta := &pb.NodeMeta{
-			Host:     node.Ip,
-			Port:     int32(node.Port),
-			Username: node.UserName,
-			Password: node.Password,
-			Tag:      node.Tag,
-			Group:    node.GroupName,
+			Host:        node.Ip,
+			Port:        int32(node.Port),
+			Username:    node.UserName,
+			Password:    node.Password,
+			Key:         node.Key,
+			Passphrase:  node.Passphrase,
+			AgentSocket: node.AgentSocket,
+			Tag:         node.Tag,
+			Group:       node.GroupName,
+			HostKey:     meta.FetchHostKey(node.Ip),
+		}
+		if !withCredential {
+			nodeMeta.Password, nodeMeta.Key, nodeMeta.Passphrase, nodeMeta.AgentSocket = "", "", "", ""
 		}
 		log.Info("query node:", nodeMeta.Host, ",port:", nodeMeta.Port)
 		res.NodeMetas = append(res.NodeMetas, nodeMeta)
 	}
-	if nodeCount == uint64(len(accessHosts)) {
-		return nil, errors.New("empty nodes")
-	}
-	if s.userPrivilege[in.Username].IsNeedUpateCache {
-		s.userPrivilege[in.Username].IsNeedUpateCache = false
+	if s.userPrivilege[username].IsNeedUpateCache {
+		s.userPrivilege[username].IsNeedUpateCache = false
 	}
 	return res, nil
 
 }
+
+// HostKey re-accepts the current host key of hosts,used after a key rotation.
+func (s *Server) HostKey(ctx context.Context, in *pb.HostKeyRequest) (*pb.HostKeyResponse, error) {
+	username, ok := s.checkSuperPermission(ctx, in.Username)
+	if !ok {
+		return nil, errors.New("Permission denied")
+	}
+	if len(in.Hosts) == 0 {
+		return nil, errors.New("empty hosts")
+	}
+	resp := &pb.HostKeyResponse{
+		Response: make([]*pb.Response, 0),
+	}
+	count := uint64(0)
+	for _, ip := range in.Hosts {
+		response := &pb.Response{
+			Addr: ip,
+		}
+		resp.Response = append(resp.Response, response)
+		node := meta.FetchNode(ip)
+		if node == nil {
+			response.Msg = "node not exists"
+			continue
+		}
+		response.Group = node.GroupName
+		hostKey, err := utils.ValidSshServer(node)
+		if err != nil {
+			log.Error("valid ", ip, ":", err)
+			response.Msg = "failed"
+			continue
+		}
+		if err = meta.UpdateHostKey(ip, hostKey); err != nil {
+			response.Msg = err.Error()
+			continue
+		}
+		atomic.AddUint64(&count, 1)
+		response.Msg = fmt.Sprintf("accept %s", ssh.Fingerprint(hostKey))
+		log.Info(username, " accept host key of ", ip, ":", hostKey)
+	}
+	if count > 0 {
+		s.mutex.Lock()
+		for _, userInfo := range s.userPrivilege {
+			userInfo.IsNeedUpateCache = true
+		}
+		s.mutex.Unlock()
+	}
+	return resp, nil
+}
 func (s *Server) Stop() {
 	s.stop <- struct{}{}
 }
 func (s *Server) fetchCluster() (*utils.Cluster, error) {
-	var group *meta.Group
-	if group = meta.FetchGroup(); group == nil {
+	group, err := meta.FetchGroup()
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
 		return nil, errors.New("group is nil")
 	}
 	c := &utils.Cluster{
@@ -657,11 +938,15 @@ func (s *Server) Run() {
 	defer s.wg.Done()
 	listen, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
 	if err != nil {
-		log.Fatal("failed to listen: %v", err)
+		log.Fatal("failed to listen: ", err)
 
 	}
 	done := make(chan struct{})
-	srv := grpc.NewServer()
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(s.auditInterceptor)}
+	if s.creds != nil {
+		opts = append(opts, grpc.Creds(s.creds))
+	}
+	srv := grpc.NewServer(opts...)
 	pb.RegisterServerNodeServiceServer(srv, s)
 	go func(srv *grpc.Server) {
 		log.Info("server start at ", s.port)
@@ -669,9 +954,14 @@ func (s *Server) Run() {
 			log.Fatal("failed to server: ", err)
 		}
 	}(srv)
+	if !s.verifyClient {
+		log.Warn("client identity is self-reported,start with -tls_client_ca to verify callers by certificate")
+	}
 	go s.reloadAuthorityConfig(done)
 	ticker := time.NewTicker(s.timeOut)
 	defer ticker.Stop()
+	grantTicker := time.NewTicker(grantCheckInterval)
+	defer grantTicker.Stop()
 	defer srv.Stop()
 	for {
 		select {
@@ -685,6 +975,8 @@ func (s *Server) Run() {
 			} else {
 				log.Info("dump success ")
 			}
+		case <-grantTicker.C:
+			s.expireGrants()
 		}
 	}
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"db"
+	"encode"
 	"sync"
 	"testing"
 	"time"
@@ -23,3 +24,27 @@ func TestStart(t *testing.T) {
 		}
 	}
 }
+
+func TestCacheKey(t *testing.T) {
+	secret, _ := encode.NewCipher(encode.DeriveKey("secret"))
+	s := &Server{cacheTTL: time.Hour, cacheCipher: secret}
+	start := time.Unix(1700000000/3600*3600, 0)
+	key, expireAt := s.cacheKey("root", start)
+	if expireAt != start.Add(time.Hour).Unix() {
+		t.Fatal("unexpected expire at ", expireAt)
+	}
+	if later, _ := s.cacheKey("root", start.Add(59*time.Minute)); string(later) != string(key) {
+		t.Fatal("key changed within the window")
+	}
+	if next, _ := s.cacheKey("root", start.Add(time.Hour)); string(next) == string(key) {
+		t.Fatal("key not rotated in the next window")
+	}
+	if other, _ := s.cacheKey("guest", start); string(other) == string(key) {
+		t.Fatal("users share a cache key")
+	}
+	other, _ := encode.NewCipher(encode.DeriveKey("other"))
+	s.cacheCipher = other
+	if another, _ := s.cacheKey("root", start); string(another) == string(key) {
+		t.Fatal("servers with different secrets share a cache key")
+	}
+}
@@ -0,0 +1,170 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	log "logging"
+	"meta"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// agentDisabled keeps the agent sockets of nodes unused,see DisableAgent.
+var agentDisabled bool
+
+// DisableAgent makes this process skip ssh-agent auth. The socket of a node
+// names the agent of the client,vsh_server would use the agent of whoever
+// runs it instead.
+func DisableAgent() {
+	agentDisabled = true
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+func nodeSigner(node *meta.Node) (ssh.Signer, error) {
+	pemBytes := []byte(node.Key)
+	if len(pemBytes) == 0 {
+		b, err := ioutil.ReadFile(expandHome(node.KeyPath))
+		if err != nil {
+			return nil, err
+		}
+		pemBytes = b
+	}
+	if len(node.Passphrase) > 0 {
+		return ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(node.Passphrase))
+	}
+	return ssh.ParsePrivateKey(pemBytes)
+}
+
+// authMethods returns the auth methods of node in the order they are tried:
+// private key, ssh-agent and then password. The returned connection to the
+// agent, if any, must be closed by the caller once the handshake is done. An
+// agent that can not be reached is logged and skipped, it is the error when
+// no other method is left. With DisableAgent a node with an agent only fails.
+func authMethods(node *meta.Node) ([]ssh.AuthMethod, net.Conn, error) {
+	methods := make([]ssh.AuthMethod, 0)
+	if len(node.Key) > 0 || len(node.KeyPath) > 0 {
+		signer, err := nodeSigner(node)
+		if err != nil {
+			return nil, nil, fmt.Errorf("private key of %s: %v", node.Ip, err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	var agentConn net.Conn
+	var agentErr error
+	if socket := os.ExpandEnv(node.AgentSocket); len(socket) > 0 && !agentDisabled {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			agentConn = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		} else {
+			agentErr = fmt.Errorf("ssh-agent of %s: %v", node.Ip, err)
+		}
+	}
+	if len(node.Password) > 0 {
+		methods = append(methods, ssh.Password(node.Password))
+	}
+	if len(methods) == 0 {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		if agentErr != nil {
+			return nil, nil, agentErr
+		}
+		if agentDisabled && node.AgentOnly() {
+			return nil, nil, fmt.Errorf("%s authenticates with ssh-agent only,which vsh_server does not use; the client must log in to it directly", node.Ip)
+		}
+		return nil, nil, errors.New(fmt.Sprintf("no usable auth method for %s", node.Ip))
+	}
+	if agentErr != nil {
+		log.Warn(agentErr, ",trying the other auth methods")
+	}
+	return methods, agentConn, nil
+}
+
+// HostKeyError is returned when a node presents a host key different from
+// the one recorded by the server.
+type HostKeyError struct {
+	Ip       string
+	Expected string
+	Got      string
+}
+
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("host key of %s changed: expected %s, got %s; if the key was rotated ask a super user to run `vsh hostkey %s`", e.Ip, e.Expected, e.Got, e.Ip)
+}
+
+// Fingerprint returns the SHA256 fingerprint of a key in authorized_keys format.
+func Fingerprint(authorizedKey string) string {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return authorizedKey
+	}
+	return ssh.FingerprintSHA256(key)
+}
+
+// Dial opens an ssh connection to node with its configured credentials.
+func Dial(node *meta.Node, timeout time.Duration) (*ssh.Client, error) {
+	client, _, err := DialWithHostKey(node, timeout)
+	return client, err
+}
+
+// DialWithHostKey is like Dial and also returns the host key presented by the
+// node in authorized_keys format. When node.HostKey is set, any other key is
+// rejected with a *HostKeyError; otherwise the presented key is trusted.
+func DialWithHostKey(node *meta.Node, timeout time.Duration) (*ssh.Client, string, error) {
+	methods, agentConn, err := authMethods(node)
+	if err != nil {
+		return nil, "", err
+	}
+	if agentConn != nil {
+		defer agentConn.Close()
+	}
+	var presented string
+	var mismatch *HostKeyError
+	sshConfig := &ssh.ClientConfig{
+		User: node.UserName,
+		Auth: methods,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			presented = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+			if len(node.HostKey) == 0 {
+				return nil
+			}
+			if strings.Compare(presented, strings.TrimSpace(node.HostKey)) != 0 {
+				mismatch = &HostKeyError{
+					Ip:       node.Ip,
+					Expected: Fingerprint(node.HostKey),
+					Got:      ssh.FingerprintSHA256(key),
+				}
+				return mismatch
+			}
+			return nil
+		},
+		Timeout: timeout,
+	}
+	addrInfo := fmt.Sprintf("%s:%d", node.Ip, node.Port)
+	client, err := ssh.Dial("tcp", addrInfo, sshConfig)
+	if mismatch != nil {
+		return nil, presented, mismatch
+	}
+	if err != nil {
+		return nil, presented, err
+	}
+	return client, presented, nil
+}
@@ -0,0 +1,211 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"meta"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func testKey(t *testing.T, passphrase string) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if len(passphrase) > 0 {
+		if block, err = x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte(passphrase), x509.PEMCipherAES256); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return key, string(pem.EncodeToMemory(block))
+}
+
+// serveAgent runs an ssh-agent holding key on a socket in dir until the
+// listener is closed.
+func serveAgent(t *testing.T, dir string, key *rsa.PrivateKey) (string, net.Listener) {
+	socket := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err = keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	return socket, l
+}
+
+func TestAuthMethods(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key, plain := testKey(t, "")
+	_, sealed := testKey(t, "secret")
+	keyPath := filepath.Join(dir, "id_rsa")
+	if err = ioutil.WriteFile(keyPath, []byte(plain), 0600); err != nil {
+		t.Fatal(err)
+	}
+	socket, l := serveAgent(t, dir, key)
+	defer l.Close()
+	missing := filepath.Join(dir, "missing.sock")
+	cases := []struct {
+		name    string
+		node    *meta.Node
+		methods int
+		agent   bool
+		err     string
+	}{
+		{"password", &meta.Node{Password: "pwd"}, 1, false, ""},
+		{"key", &meta.Node{Key: plain}, 1, false, ""},
+		{"key file", &meta.Node{KeyPath: keyPath}, 1, false, ""},
+		{"key with passphrase", &meta.Node{Key: sealed, Passphrase: "secret"}, 1, false, ""},
+		{"key with wrong passphrase", &meta.Node{Key: sealed, Passphrase: "wrong"}, 0, false, "private key"},
+		{"key without passphrase", &meta.Node{Key: sealed}, 0, false, "private key"},
+		{"missing key file", &meta.Node{KeyPath: filepath.Join(dir, "none")}, 0, false, "private key"},
+		{"agent", &meta.Node{AgentSocket: socket}, 1, true, ""},
+		{"all", &meta.Node{Key: plain, AgentSocket: socket, Password: "pwd"}, 3, true, ""},
+		{"unreachable agent", &meta.Node{AgentSocket: missing}, 0, false, "ssh-agent"},
+		{"unreachable agent and password", &meta.Node{AgentSocket: missing, Password: "pwd"}, 1, false, ""},
+		{"nothing", &meta.Node{}, 0, false, "no usable auth method"},
+	}
+	for _, c := range cases {
+		c.node.Ip = "10.0.0.1"
+		methods, agentConn, err := authMethods(c.node)
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		if len(c.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expect error with %q,got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil || len(methods) != c.methods || (agentConn != nil) != c.agent {
+			t.Errorf("%s: expect %d methods,agent %v,got %d,%v,%v", c.name, c.methods, c.agent, len(methods), agentConn != nil, err)
+		}
+	}
+	// vsh_server never uses the agent it runs with
+	DisableAgent()
+	defer func() { agentDisabled = false }()
+	if _, _, err = authMethods(&meta.Node{Ip: "10.0.0.1", AgentSocket: socket}); err == nil || !strings.Contains(err.Error(), "ssh-agent only") {
+		t.Error("expect agent only node refused,got ", err)
+	}
+	methods, agentConn, err := authMethods(&meta.Node{Ip: "10.0.0.1", AgentSocket: socket, Password: "pwd"})
+	if err != nil || len(methods) != 1 || agentConn != nil {
+		t.Error("expect the password only,got ", len(methods), agentConn != nil, err)
+	}
+}
+
+// serveSSH accepts ssh connections with password pwd on a local port,it
+// presents the host key of hostKey.
+func serveSSH(t *testing.T, hostKey *rsa.PrivateKey) (*meta.Node, net.Listener) {
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "pwd" {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no channels")
+				}
+			}()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &meta.Node{Ip: addr.IP.String(), Port: addr.Port, UserName: "root", Password: "pwd"}, l
+}
+
+func TestDialHostKey(t *testing.T) {
+	first, _ := testKey(t, "")
+	node, l := serveSSH(t, first)
+	// first use trusts and returns the presented key
+	client, recorded, err := DialWithHostKey(node, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	if len(recorded) == 0 {
+		t.Fatal("no host key presented")
+	}
+	node.HostKey = recorded
+	if client, err = Dial(node, 5*time.Second); err != nil {
+		t.Fatal("recorded key rejected:", err)
+	}
+	client.Close()
+	l.Close()
+
+	// the node comes back with a rotated key on the same address
+	rotated, _ := testKey(t, "")
+	rotatedNode, l := serveSSH(t, rotated)
+	defer l.Close()
+	node.Port = rotatedNode.Port
+	_, presented, err := DialWithHostKey(node, 5*time.Second)
+	mismatch, ok := err.(*HostKeyError)
+	if !ok {
+		t.Fatal("expect a host key error,got ", err)
+	}
+	if mismatch.Ip != node.Ip || mismatch.Expected != Fingerprint(recorded) || mismatch.Got != Fingerprint(presented) {
+		t.Fatal("unexpected mismatch ", mismatch)
+	}
+	if errorKind(err) != KindHostKey {
+		t.Fatal("expect kind hostkey,got ", errorKind(err))
+	}
+	// vsh hostkey dials without the recorded key and accepts the new one
+	node.HostKey = ""
+	if client, presented, err = DialWithHostKey(node, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	node.HostKey = presented
+	if client, err = Dial(node, 5*time.Second); err != nil {
+		t.Fatal("accepted key rejected:", err)
+	}
+	client.Close()
+}
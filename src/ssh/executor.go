@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"meta"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	DefaultParallel = 10
+)
+
+// Executor runs one command on many nodes with a bounded number of
+// concurrent ssh sessions.
+type Executor struct {
+	parallel int
+	timeout  time.Duration
+}
+
+func NewExecutor(parallel int, timeout time.Duration) *Executor {
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+	return &Executor{
+		parallel: parallel,
+		timeout:  timeout,
+	}
+}
+
+// Execute starts cmd on nodes and returns a channel yielding one Result per
+// node in completion order. The channel is closed once every node is done.
+func (e *Executor) Execute(nodes []*meta.Node, cmd string) <-chan *Result {
+	return e.ExecuteContext(context.Background(), nodes, cmd)
+}
+
+// ExecuteContext is Execute that stops the commands once ctx is canceled,
+// nodes not started by then are not dialed and fail as canceled.
+func (e *Executor) ExecuteContext(ctx context.Context, nodes []*meta.Node, cmd string) <-chan *Result {
+	return e.each(nodes, func(node *meta.Node) *Result {
+		if err := ctx.Err(); err != nil {
+			return failedResult(node, cmd, err)
+		}
+		return RunContext(ctx, node, cmd, e.timeout)
+	})
+}
+
+// Push copies local to remote on nodes,see Execute for the results.
+func (e *Executor) Push(nodes []*meta.Node, local, remote string) <-chan *Result {
+	return e.each(nodes, func(node *meta.Node) *Result {
+		start := time.Now()
+		stat, err := Push(node, local, remote, e.timeout)
+		return transferResult(node, "push "+local+" "+remote, stat, err, start)
+	})
+}
+
+// Pull copies remote on nodes into a subdirectory of localDir named after
+// each node,see Execute for the results.
+func (e *Executor) Pull(nodes []*meta.Node, remote, localDir string) <-chan *Result {
+	return e.each(nodes, func(node *meta.Node) *Result {
+		start := time.Now()
+		dir := filepath.Join(localDir, node.Ip)
+		stat, err := Pull(node, remote, dir, e.timeout)
+		return transferResult(node, "pull "+remote+" "+dir, stat, err, start)
+	})
+}
+
+func transferResult(node *meta.Node, cmd string, stat *TransferStat, err error, start time.Time) *Result {
+	res := &Result{
+		Node:     node,
+		Cmd:      cmd,
+		Transfer: stat,
+		Duration: time.Since(start),
+	}
+	res.SetErr(err)
+	return res
+}
+
+func (e *Executor) each(nodes []*meta.Node, fn func(node *meta.Node) *Result) <-chan *Result {
+	results := make(chan *Result, len(nodes))
+	jobs := make(chan *meta.Node)
+	workers := e.parallel
+	if workers > len(nodes) {
+		workers = len(nodes)
+	}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range jobs {
+				results <- fn(node)
+			}
+		}()
+	}
+	go func() {
+		for _, node := range nodes {
+			jobs <- node
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
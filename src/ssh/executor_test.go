@@ -0,0 +1,81 @@
+package ssh
+
+import (
+	"meta"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestExecuteUnreachable(t *testing.T) {
+	nodes := make([]*meta.Node, 0)
+	for i := 0; i < 5; i++ {
+		nodes = append(nodes, &meta.Node{
+			Ip:       "127.0.0.1",
+			Port:     1,
+			UserName: "root",
+			Password: "test",
+		})
+	}
+	e := NewExecutor(2, time.Second)
+	count := 0
+	for res := range e.Execute(nodes, "uptime") {
+		if res.Err == nil {
+			t.Fatal("expect dial error for ", res.Node.Ip)
+		}
+		if res.Kind != KindUnreachable || res.Status() != StatusUnreachable {
+			t.Fatalf("got kind %q status %s, expect unreachable", res.Kind, res.Status())
+		}
+		count++
+	}
+	if count != len(nodes) {
+		t.Fatalf("got %d results, expect %d", count, len(nodes))
+	}
+}
+
+func TestExecuteCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	nodes := []*meta.Node{{Ip: "192.0.2.1", Port: 22}, {Ip: "192.0.2.2", Port: 22}}
+	count := 0
+	for res := range NewExecutor(2, time.Minute).ExecuteContext(ctx, nodes, "uptime") {
+		if res.Kind != KindCanceled {
+			t.Fatalf("got kind %q for %s, expect canceled", res.Kind, res.Node.Ip)
+		}
+		count++
+	}
+	if count != len(nodes) {
+		t.Fatalf("got %d results, expect %d", count, len(nodes))
+	}
+}
+
+// a node that accepts the connection but never answers the handshake still
+// fails once the timeout elapsed.
+func TestRunTimeoutCoversDial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	node := &meta.Node{Ip: addr.IP.String(), Port: addr.Port, UserName: "root", Password: "test"}
+	timeout := 300 * time.Millisecond
+	res := RunWithTimeout(node, "uptime", timeout)
+	if res.Kind != KindTimeout {
+		t.Fatalf("got kind %q, expect timeout: %v", res.Kind, res.Err)
+	}
+	if res.Duration > 2*timeout {
+		t.Fatalf("gave up after %v, timeout %v", res.Duration, timeout)
+	}
+}
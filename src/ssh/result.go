@@ -0,0 +1,123 @@
+package ssh
+
+import (
+	"fmt"
+	"meta"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/context"
+)
+
+// ErrorKind tells why a command did not succeed on a node.
+type ErrorKind string
+
+const (
+	KindNone        ErrorKind = ""
+	KindExit        ErrorKind = "exit"        //ran and exited non-zero
+	KindSignal      ErrorKind = "signal"      //killed by a signal
+	KindTimeout     ErrorKind = "timeout"     //did not finish in time
+	KindUnreachable ErrorKind = "unreachable" //no connection to the node
+	KindAuth        ErrorKind = "auth"        //the node refused the credentials
+	KindHostKey     ErrorKind = "hostkey"     //the node presented another host key
+	KindDenied      ErrorKind = "denied"      //vsh_server refused to run it
+	KindSession     ErrorKind = "session"     //connected but the session broke
+	KindCanceled    ErrorKind = "canceled"    //stopped by the user
+)
+
+const (
+	StatusSucceeded   = "succeeded"
+	StatusFailed      = "failed"
+	StatusUnreachable = "unreachable"
+)
+
+// Result is what running a command,or a push or pull,did on one node.
+type Result struct {
+	Node       *meta.Node
+	Cmd        string
+	Stdout     []byte
+	Stderr     []byte
+	ExitStatus int           //-1 when the command did not run to the end
+	Signal     string        //such as KILL when a signal ended the command
+	Kind       ErrorKind     //empty when Err is nil
+	Transfer   *TransferStat //what push or pull copied
+	Err        error
+	Duration   time.Duration
+}
+
+// Status sorts the result into succeeded,failed or unreachable,a node that
+// refused the login counts as unreachable.
+func (r *Result) Status() string {
+	switch {
+	case r.Err == nil:
+		return StatusSucceeded
+	case r.Kind == KindUnreachable || r.Kind == KindAuth || r.Kind == KindHostKey:
+		return StatusUnreachable
+	}
+	return StatusFailed
+}
+
+// SetErr records err with its kind,exit status and signal.
+func (r *Result) SetErr(err error) {
+	r.Err = err
+	r.Kind = errorKind(err)
+	r.ExitStatus = -1
+	r.Signal = ""
+	switch e := err.(type) {
+	case nil:
+		r.ExitStatus = 0
+	case *ssh.ExitError:
+		r.ExitStatus = e.ExitStatus()
+		r.Signal = e.Signal()
+	}
+}
+
+// failedResult is the result of cmd on a node it never ran on.
+func failedResult(node *meta.Node, cmd string, err error) *Result {
+	res := &Result{Node: node, Cmd: cmd}
+	res.SetErr(err)
+	return res
+}
+
+type timeoutError struct {
+	what    string
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%s timeout after %v", e.what, e.timeout)
+}
+
+func errorKind(err error) ErrorKind {
+	if err == context.Canceled {
+		return KindCanceled
+	}
+	switch e := err.(type) {
+	case nil:
+		return KindNone
+	case *ssh.ExitError:
+		if len(e.Signal()) > 0 {
+			return KindSignal
+		}
+		return KindExit
+	case *ssh.ExitMissingError:
+		return KindSession
+	case *timeoutError:
+		return KindTimeout
+	case *HostKeyError:
+		return KindHostKey
+	case net.Error:
+		return KindUnreachable
+	}
+	// errors of ssh.Dial are only told apart by their text
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "unable to authenticate"):
+		return KindAuth
+	case strings.HasPrefix(msg, "ssh: handshake failed"):
+		return KindUnreachable
+	}
+	return KindSession
+}
@@ -0,0 +1,48 @@
+package ssh
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrorKind(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{nil, KindNone},
+		{&timeoutError{what: "command", timeout: time.Second}, KindTimeout},
+		{&HostKeyError{}, KindHostKey},
+		{errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none password]"), KindAuth},
+		{errors.New("ssh: handshake failed: EOF"), KindUnreachable},
+		{errors.New("session broke"), KindSession},
+	} {
+		if kind := errorKind(c.err); kind != c.kind {
+			t.Errorf("errorKind(%v) = %q, expect %q", c.err, kind, c.kind)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, c := range []struct {
+		kind   ErrorKind
+		status string
+	}{
+		{KindExit, StatusFailed},
+		{KindSignal, StatusFailed},
+		{KindTimeout, StatusFailed},
+		{KindDenied, StatusFailed},
+		{KindAuth, StatusUnreachable},
+		{KindHostKey, StatusUnreachable},
+		{KindUnreachable, StatusUnreachable},
+	} {
+		r := &Result{Err: errors.New(string(c.kind)), Kind: c.kind}
+		if status := r.Status(); status != c.status {
+			t.Errorf("kind %s got status %s, expect %s", c.kind, status, c.status)
+		}
+	}
+	if status := (&Result{}).Status(); status != StatusSucceeded {
+		t.Errorf("got status %s, expect %s", status, StatusSucceeded)
+	}
+}
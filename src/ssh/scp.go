@@ -0,0 +1,359 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"meta"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// TransferStat counts what one push or pull copied.
+type TransferStat struct {
+	Files int
+	Bytes int64
+}
+
+func (t *TransferStat) String() string {
+	return fmt.Sprintf("%d files,%d bytes", t.Files, t.Bytes)
+}
+
+// quotePath quotes path for the remote shell,keeping a leading ~/ so it still
+// expands to the home of the login user.
+func quotePath(path string) string {
+	prefix := ""
+	if strings.HasPrefix(path, "~/") {
+		prefix, path = "~/", path[2:]
+	}
+	if len(path) == 0 {
+		return prefix
+	}
+	return prefix + ShellQuote(path)
+}
+
+// ShellQuote quotes s as a single word for a posix shell.
+func ShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// readAck reads the reply of the remote scp to the last message.
+func readAck(r *bufio.Reader) error {
+	b, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if b == 0 {
+		return nil
+	}
+	// the message of the remote scp starts with scp: itself
+	msg, _ := r.ReadString('\n')
+	return errors.New(strings.TrimSpace(msg))
+}
+
+// scpSession starts the remote scp command on node and hands its stdin and
+// stdout to fn. The remote stderr is added to the error,timeout bounds the
+// whole transfer and zero waits until it is done.
+func scpSession(node *meta.Node, cmd string, timeout time.Duration, fn func(w io.Writer, r *bufio.Reader) error) error {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	client, err := dialUntil(context.Background(), node, timeout, expired, "transfer")
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	if err = session.Start(cmd); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := fn(stdin, bufio.NewReader(stdout))
+		stdin.Close()
+		if waitErr := session.Wait(); err == nil {
+			err = waitErr
+		}
+		done <- err
+	}()
+	select {
+	case err = <-done:
+	case <-expired:
+		client.Close()
+		<-done
+		err = &timeoutError{what: "transfer", timeout: timeout}
+	}
+	if _, timedOut := err.(*timeoutError); err != nil && !timedOut && stderr.Len() > 0 {
+		err = fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+// Push copies the local file or directory to remote on node with scp,keeping
+// file modes and modification times. Directories are copied recursively.
+func Push(node *meta.Node, local, remote string, timeout time.Duration) (*TransferStat, error) {
+	info, err := os.Stat(local)
+	if err != nil {
+		return nil, err
+	}
+	cmd := "scp -t -p "
+	if info.IsDir() {
+		cmd = "scp -t -p -r "
+	}
+	stat := &TransferStat{}
+	err = scpSession(node, cmd+quotePath(remote), timeout, func(w io.Writer, r *bufio.Reader) error {
+		if err := readAck(r); err != nil {
+			return err
+		}
+		return sendEntry(w, r, local, info, stat)
+	})
+	return stat, err
+}
+
+func sendEntry(w io.Writer, r *bufio.Reader, path string, info os.FileInfo, stat *TransferStat) error {
+	mtime := info.ModTime().Unix()
+	if _, err := fmt.Fprintf(w, "T%d 0 %d 0\n", mtime, mtime); err != nil {
+		return err
+	}
+	if err := readAck(r); err != nil {
+		return err
+	}
+	mode := info.Mode().Perm()
+	if !info.IsDir() {
+		return sendFile(w, r, path, info, stat)
+	}
+	if _, err := fmt.Fprintf(w, "D%04o 0 %s\n", mode, info.Name()); err != nil {
+		return err
+	}
+	if err := readAck(r); err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	entries, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		// links and devices have no scp message,follow links like scp does
+		if entry.Mode()&os.ModeSymlink != 0 {
+			if entry, err = os.Stat(filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+		if !entry.IsDir() && !entry.Mode().IsRegular() {
+			continue
+		}
+		if err = sendEntry(w, r, filepath.Join(path, entry.Name()), entry, stat); err != nil {
+			return err
+		}
+	}
+	if _, err = io.WriteString(w, "E\n"); err != nil {
+		return err
+	}
+	return readAck(r)
+}
+
+func sendFile(w io.Writer, r *bufio.Reader, path string, info os.FileInfo, stat *TransferStat) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = fmt.Fprintf(w, "C%04o %d %s\n", info.Mode().Perm(), info.Size(), info.Name()); err != nil {
+		return err
+	}
+	if err = readAck(r); err != nil {
+		return err
+	}
+	n, err := io.CopyN(w, f, info.Size())
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write([]byte{0}); err != nil {
+		return err
+	}
+	if err = readAck(r); err != nil {
+		return err
+	}
+	stat.Files++
+	stat.Bytes += n
+	return nil
+}
+
+// Pull copies the remote file or directory on node into localDir with scp,
+// keeping file modes and modification times.
+func Pull(node *meta.Node, remote, localDir string, timeout time.Duration) (*TransferStat, error) {
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return nil, err
+	}
+	stat := &TransferStat{}
+	err := scpSession(node, "scp -f -p -r "+quotePath(remote), timeout, func(w io.Writer, r *bufio.Reader) error {
+		return receive(w, r, localDir, stat)
+	})
+	return stat, err
+}
+
+// receive is the sink side of scp,it writes what the remote scp sends below dir.
+func receive(w io.Writer, r *bufio.Reader, dir string, stat *TransferStat) error {
+	ack := func() error {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	if err := ack(); err != nil {
+		return err
+	}
+	type openDir struct {
+		path  string
+		mode  os.FileMode
+		mtime time.Time
+	}
+	dirs := []openDir{{path: dir}}
+	var mtime time.Time //from the T message before a C or D
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && len(line) == 0 {
+			if len(dirs) > 1 {
+				return errors.New("scp: unexpected end of directory")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) == 0 {
+			return errors.New("scp: empty message")
+		}
+		current := dirs[len(dirs)-1].path
+		switch line[0] {
+		case 1, 2:
+			return errors.New(strings.TrimSpace(line[1:]))
+		case 'T':
+			fields := strings.Fields(line[1:])
+			if len(fields) != 4 {
+				return fmt.Errorf("scp: invalid times %q", line)
+			}
+			sec, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("scp: invalid times %q", line)
+			}
+			mtime = time.Unix(sec, 0)
+		case 'E':
+			if len(dirs) == 1 {
+				return errors.New("scp: unexpected end of directory")
+			}
+			// set after the files,a read only mode would stop them and writing
+			// them changes the time again
+			last := dirs[len(dirs)-1]
+			if err = os.Chmod(last.path, last.mode); err != nil {
+				return err
+			}
+			if !last.mtime.IsZero() {
+				os.Chtimes(last.path, last.mtime, last.mtime)
+			}
+			dirs = dirs[:len(dirs)-1]
+		case 'D':
+			mode, _, name, err := parseEntry(line)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(current, name)
+			if err = os.MkdirAll(path, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, openDir{path: path, mode: mode, mtime: mtime})
+			mtime = time.Time{}
+		case 'C':
+			mode, size, name, err := parseEntry(line)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(current, name)
+			if err = ack(); err != nil {
+				return err
+			}
+			if err = receiveFile(r, path, mode, size); err != nil {
+				return err
+			}
+			if err = readAck(r); err != nil {
+				return err
+			}
+			if !mtime.IsZero() {
+				os.Chtimes(path, mtime, mtime)
+				mtime = time.Time{}
+			}
+			stat.Files++
+			stat.Bytes += size
+		default:
+			return fmt.Errorf("scp: unknown message %q", line)
+		}
+		if err = ack(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseEntry parses a C or D message,names must stay inside the target dir.
+func parseEntry(line string) (os.FileMode, int64, string, error) {
+	fields := strings.SplitN(line[1:], " ", 3)
+	if len(fields) != 3 {
+		return 0, 0, "", fmt.Errorf("scp: invalid message %q", line)
+	}
+	mode, err := strconv.ParseUint(fields[0], 8, 32)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("scp: invalid mode %q", line)
+	}
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || size < 0 {
+		return 0, 0, "", fmt.Errorf("scp: invalid size %q", line)
+	}
+	name := fields[2]
+	if len(name) == 0 || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		return 0, 0, "", fmt.Errorf("scp: invalid name %q", name)
+	}
+	return os.FileMode(mode).Perm(), size, name, nil
+}
+
+func receiveFile(r io.Reader, path string, mode os.FileMode, size int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.CopyN(f, r, size); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	// the umask may have dropped bits of mode
+	return os.Chmod(path, mode)
+}
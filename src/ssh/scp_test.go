@@ -0,0 +1,48 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReceive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// acks of the source follow each file
+	stream := "T1577934245 0 1577934245 0\nC0640 5 a.txt\nhello\x00" +
+		"D0750 0 d\nC0600 2 b\nhi\x00E\n"
+	var acks bytes.Buffer
+	stat := &TransferStat{}
+	if err = receive(&acks, bufio.NewReader(strings.NewReader(stream)), dir, stat); err != nil {
+		t.Fatal(err)
+	}
+	if stat.Files != 2 || stat.Bytes != 7 {
+		t.Errorf("got %s", stat)
+	}
+	for path, mode := range map[string]os.FileMode{"a.txt": 0640, "d": 0750 | os.ModeDir, "d/b": 0600} {
+		info, err := os.Stat(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode() != mode {
+			t.Errorf("%s mode %v,expect %v", path, info.Mode(), mode)
+		}
+		if path == "a.txt" && info.ModTime().Unix() != 1577934245 {
+			t.Errorf("a.txt mtime %v", info.ModTime())
+		}
+	}
+
+	for _, bad := range []string{"C0644 1 ../x\nx\x00", "D0755 0 ..\nE\n", "C0644 1 a/b\nx\x00", "D0755 0 d\n", "\x01scp: denied\n"} {
+		if err = receive(&acks, bufio.NewReader(strings.NewReader(bad)), dir, stat); err == nil {
+			t.Errorf("%q should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ssh
+
+import (
+	"io"
+	"meta"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Shell is a login shell on a node in a pty of its own,driven by vsh instead
+// of being attached to the local terminal. vsh broadcast runs one per node.
+type Shell struct {
+	Node    *meta.Node
+	Session Session
+	Stdin   io.WriteCloser
+	Output  io.Reader //stdout and stderr of the pty
+	output  *io.PipeReader
+	client  *ssh.Client //nil when the session is relayed by vsh_server
+}
+
+// OpenShell dials node and starts a shell in a pty of width x height.
+func OpenShell(node *meta.Node, width, height int, timeout time.Duration) (*Shell, error) {
+	client, err := Dial(node, timeout)
+	if err != nil {
+		return nil, err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	shell, err := StartShell(node, session, width, height)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	shell.client = client
+	return shell, nil
+}
+
+// StartShell starts a shell in a pty of width x height on session,such as a
+// session relayed by vsh_server. session is closed when it fails.
+func StartShell(node *meta.Node, session Session, width, height int) (*Shell, error) {
+	shell, err := startShell(node, session, width, height)
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	return shell, nil
+}
+
+func startShell(node *meta.Node, session Session, width, height int) (*Shell, error) {
+	if err := RequestPty(session, width, height); err != nil {
+		return nil, err
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = session.Shell(); err != nil {
+		return nil, err
+	}
+	// a pty sends nearly all to stdout,stderr still has to be drained
+	reader, writer := io.Pipe()
+	wg := &sync.WaitGroup{}
+	for _, r := range []io.Reader{stdout, stderr} {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			io.Copy(writer, r)
+		}(r)
+	}
+	go func() {
+		wg.Wait()
+		writer.Close()
+	}()
+	return &Shell{Node: node, Session: session, Stdin: stdin, Output: reader, output: reader}, nil
+}
+
+// Resize changes the size of the pty.
+func (s *Shell) Resize(width, height int) error {
+	return s.Session.WindowChange(height, width)
+}
+
+// Close ends the shell and its connection,Output stops being read then.
+func (s *Shell) Close() error {
+	s.output.Close()
+	err := s.Session.Close()
+	if s.client != nil {
+		s.client.Close()
+	}
+	return err
+}
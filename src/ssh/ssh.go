@@ -1,73 +1,159 @@
 package ssh
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"meta"
 	"os"
 	"os/signal"
+	"record"
 	"syscall"
 	"time"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/net/context"
 )
 
+// Session is the part of *ssh.Session an interactive terminal needs,so the
+// terminal also runs over a session relayed by vsh_server.
+type Session interface {
+	RequestPty(term string, h, w int, termmodes ssh.TerminalModes) error
+	WindowChange(h, w int) error
+	StdinPipe() (io.WriteCloser, error)
+	StdoutPipe() (io.Reader, error)
+	StderrPipe() (io.Reader, error)
+	Shell() error
+	Wait() error
+	Close() error
+}
+
 type SSHTerminal struct {
-	Session *ssh.Session
-	exitMsg string
-	stdout  io.Reader
-	stdin   io.Writer
-	stderr  io.Reader
+	Session  Session
+	Recorder *record.Recorder //optional,records the session output
+	exitMsg  string
+	stdout   io.Reader
+	stdin    io.Writer
+	stderr   io.Reader
 }
 
-func NewSSHConnection(node *meta.Node) error {
-	sshConfig := &ssh.ClientConfig{
-		User: node.UserName,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(node.Password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	addrInfo := fmt.Sprintf("%s:%d", node.Ip, node.Port)
-	client, err := ssh.Dial("tcp", addrInfo, sshConfig)
+// NewSSHConnection opens an interactive shell on node,recording it with rec
+// when rec is not nil.
+func NewSSHConnection(node *meta.Node, rec *record.Recorder) error {
+	client, err := Dial(node, 0)
 	if err != nil {
 		return err
 	}
 	defer client.Close()
 
-	err = NewSShClient(client)
+	err = NewSShClient(client, rec)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func Run(node *meta.Node, cmd string) ([]byte, error) {
-	sshConfig := &ssh.ClientConfig{
-		User: node.UserName,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(node.Password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+// Run executes cmd on node and waits for it to finish.
+func Run(node *meta.Node, cmd string) *Result {
+	return RunWithTimeout(node, cmd, 0)
+}
+
+// RunWithTimeout executes cmd on node, giving up once timeout has elapsed.
+// A zero timeout waits for the command to finish. Stdout and stderr are kept
+// apart and returned also when the command fails or times out.
+func RunWithTimeout(node *meta.Node, cmd string, timeout time.Duration) *Result {
+	return RunContext(context.Background(), node, cmd, timeout)
+}
+
+// RunContext is RunWithTimeout that also stops once ctx is canceled.
+func RunContext(ctx context.Context, node *meta.Node, cmd string, timeout time.Duration) *Result {
+	var stdout, stderr bytes.Buffer
+	res := runSession(ctx, node, cmd, timeout, &stdout, &stderr)
+	res.Stdout, res.Stderr = stdout.Bytes(), stderr.Bytes()
+	return res
+}
+
+// runSession executes cmd on node writing its output to stdout and stderr,
+// until it finishes,timeout elapses or ctx is canceled. The timeout covers
+// connecting and running together.
+func runSession(ctx context.Context, node *meta.Node, cmd string, timeout time.Duration, stdout, stderr io.Writer) *Result {
+	start := time.Now()
+	res := &Result{Node: node, Cmd: cmd}
+	defer func() {
+		res.Duration = time.Since(start)
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
 	}
-	addrInfo := fmt.Sprintf("%s:%d", node.Ip, node.Port)
-	client, err := ssh.Dial("tcp", addrInfo, sshConfig)
+	client, err := dialUntil(ctx, node, timeout, expired, "command")
 	if err != nil {
-		return nil, err
+		res.SetErr(err)
+		return res
 	}
 	defer client.Close()
 	session, err := client.NewSession()
 	if err != nil {
-		return nil, err
+		res.SetErr(err)
+		return res
 	}
 	defer session.Close()
-	combo, err := session.CombinedOutput(cmd)
-	if err != nil {
-		return nil, err
+	session.Stdout = stdout
+	session.Stderr = stderr
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(cmd)
+	}()
+	// closing the connection ends Run,so the writers are no longer written
+	select {
+	case err = <-done:
+	case <-expired:
+		client.Close()
+		<-done
+		err = &timeoutError{what: "command", timeout: timeout}
+	case <-ctx.Done():
+		// not every sshd passes signals on,the closed connection is the fallback
+		session.Signal(ssh.SIGTERM)
+		client.Close()
+		<-done
+		err = ctx.Err()
+	}
+	res.SetErr(err)
+	return res
+}
+
+// dialUntil dials node in the background,so a handshake that hangs is given
+// up as well once expired fires or ctx is canceled. what names the timeout.
+func dialUntil(ctx context.Context, node *meta.Node, timeout time.Duration, expired <-chan time.Time, what string) (*ssh.Client, error) {
+	type dialed struct {
+		client *ssh.Client
+		err    error
+	}
+	ch := make(chan dialed, 1)
+	go func() {
+		client, err := Dial(node, timeout)
+		ch <- dialed{client, err}
+	}()
+	var err error
+	select {
+	case d := <-ch:
+		return d.client, d.err
+	case <-expired:
+		err = &timeoutError{what: what, timeout: timeout}
+	case <-ctx.Done():
+		err = ctx.Err()
 	}
-	return combo, nil
+	go func() {
+		if d := <-ch; d.client != nil {
+			d.client.Close()
+		}
+	}()
+	return nil, err
 }
+
 func (t *SSHTerminal) updateTerminalSize() {
 
 	go func() {
@@ -98,6 +184,9 @@ func (t *SSHTerminal) updateTerminalSize() {
 				}
 
 				t.Session.WindowChange(currTermHeight, currTermWidth)
+				if t.Recorder != nil {
+					t.Recorder.Resize(currTermWidth, currTermHeight)
+				}
 
 				if err != nil {
 					fmt.Printf("Unable to send window-change reqest: %s.", err)
@@ -134,17 +223,7 @@ func (t *SSHTerminal) interactiveSession() error {
 		return err
 	}
 
-	termType := os.Getenv("TERM")
-	if termType == "" {
-		termType = "xterm-256color"
-	}
-
-	modes := ssh.TerminalModes{
-		ssh.ECHO:          1,
-		ssh.TTY_OP_ISPEED: 14400,
-		ssh.TTY_OP_OSPEED: 14400,
-	}
-	err = t.Session.RequestPty(termType, termHeight, termWidth, modes)
+	err = RequestPty(t.Session, termWidth, termHeight)
 	if err != nil {
 		return err
 	}
@@ -162,8 +241,12 @@ func (t *SSHTerminal) interactiveSession() error {
 	}
 	t.stderr, err = t.Session.StderrPipe()
 
-	go io.Copy(os.Stderr, t.stderr)
-	go io.Copy(os.Stdout, t.stdout)
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if t.Recorder != nil {
+		stdout, stderr = io.MultiWriter(os.Stdout, t.Recorder), io.MultiWriter(os.Stderr, t.Recorder)
+	}
+	go io.Copy(stderr, t.stderr)
+	go io.Copy(stdout, t.stdout)
 
 	go func() {
 		buf := make([]byte, 2048)
@@ -194,16 +277,39 @@ func (t *SSHTerminal) interactiveSession() error {
 	return nil
 }
 
-func NewSShClient(client *ssh.Client) error {
+// RequestPty asks session for a pty of width x height with the TERM of the
+// local terminal.
+func RequestPty(session Session, width, height int) error {
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = "xterm-256color"
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	return session.RequestPty(termType, height, width, modes)
+}
+
+func NewSShClient(client *ssh.Client, rec *record.Recorder) error {
 
 	session, err := client.NewSession()
 	if err != nil {
 		return err
 	}
+	return NewTerminal(session, rec)
+}
+
+// NewTerminal attaches the local terminal to session until the remote shell
+// exits,recording it with rec when rec is not nil.
+func NewTerminal(session Session, rec *record.Recorder) error {
 	defer session.Close()
 
 	s := SSHTerminal{
-		Session: session,
+		Session:  session,
+		Recorder: rec,
 	}
 
 	return s.interactiveSession()
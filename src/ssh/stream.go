@@ -0,0 +1,84 @@
+package ssh
+
+import (
+	"bytes"
+	"meta"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// maxLineSize bounds what is kept of a line not ended yet,longer lines are
+// handed on in pieces so a host never holds more than this per stream.
+const maxLineSize = 64 * 1024
+
+// LineFunc receives each line of output of node without its newline,stderr
+// tells where it came from. line is only valid during the call and the calls
+// of different nodes and streams run concurrently.
+type LineFunc func(node *meta.Node, stderr bool, line []byte)
+
+// lineWriter splits what is written to it into lines for fn.
+type lineWriter struct {
+	buf []byte
+	fn  func(line []byte)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		end := i
+		if i < 0 {
+			end = len(p)
+		}
+		if room := maxLineSize - len(w.buf); end > room {
+			w.buf = append(w.buf, p[:room]...)
+			p = p[room:]
+			w.emit()
+			continue
+		}
+		w.buf = append(w.buf, p[:end]...)
+		if i < 0 {
+			break
+		}
+		p = p[i+1:]
+		w.emit()
+	}
+	return n, nil
+}
+
+func (w *lineWriter) emit() {
+	w.fn(w.buf)
+	w.buf = w.buf[:0]
+}
+
+// Flush hands on the last line when the output did not end with a newline.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.emit()
+	}
+}
+
+// RunStream executes cmd on node like RunWithTimeout,but hands each line of
+// output to fn as it arrives instead of keeping it. Canceling ctx sends the
+// command SIGTERM and closes the connection.
+func RunStream(ctx context.Context, node *meta.Node, cmd string, timeout time.Duration, fn LineFunc) *Result {
+	stdout := &lineWriter{fn: func(line []byte) { fn(node, false, line) }}
+	stderr := &lineWriter{fn: func(line []byte) { fn(node, true, line) }}
+	res := runSession(ctx, node, cmd, timeout, stdout, stderr)
+	stdout.Flush()
+	stderr.Flush()
+	return res
+}
+
+// Stream runs cmd on nodes like Execute,handing their output to fn line by
+// line as it arrives. The results carry no output. Nodes not started when
+// ctx is canceled are not dialed and fail as canceled.
+func (e *Executor) Stream(ctx context.Context, nodes []*meta.Node, cmd string, fn LineFunc) <-chan *Result {
+	return e.each(nodes, func(node *meta.Node) *Result {
+		if err := ctx.Err(); err != nil {
+			return failedResult(node, cmd, err)
+		}
+		return RunStream(ctx, node, cmd, e.timeout, fn)
+	})
+}
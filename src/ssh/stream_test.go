@@ -0,0 +1,33 @@
+package ssh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{fn: func(line []byte) {
+		lines = append(lines, string(line))
+	}}
+	for _, chunk := range []string{"one\ntw", "o\n\nthr", "ee"} {
+		w.Write([]byte(chunk))
+	}
+	w.Flush()
+	expect := []string{"one", "two", "", "three"}
+	if strings.Join(lines, "|") != strings.Join(expect, "|") {
+		t.Fatalf("got lines %q, expect %q", lines, expect)
+	}
+
+	lines = lines[:0]
+	long := bytes.Repeat([]byte("x"), maxLineSize*2+10)
+	w.Write(append(long, '\n'))
+	w.Flush()
+	if len(lines) != 3 || len(lines[0]) != maxLineSize || len(lines[1]) != maxLineSize || len(lines[2]) != 10 {
+		t.Fatalf("got %d pieces of a long line, expect 3 of at most %d bytes", len(lines), maxLineSize)
+	}
+	if cap(w.buf) > maxLineSize {
+		t.Fatalf("buffer grew to %d, expect at most %d", cap(w.buf), maxLineSize)
+	}
+}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DefaultCACertFile   = "ca.pem"
+	DefaultCAKeyFile    = "ca-key.pem"
+	defaultCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+func writePem(path string, blockType string, b []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), perm)
+}
+
+func writeKeyPair(dir, name string, der []byte, key *ecdsa.PrivateKey) error {
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err = writePem(filepath.Join(dir, name+".pem"), "CERTIFICATE", der, 0644); err != nil {
+		return err
+	}
+	return writePem(filepath.Join(dir, name+"-key.pem"), "EC PRIVATE KEY", keyBytes, 0600)
+}
+
+func newTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"vsh"},
+			CommonName:   commonName,
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(defaultCertValidity),
+	}, nil
+}
+
+func loadCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, DefaultCACertFile), filepath.Join(dir, DefaultCAKeyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("ca key is not an ecdsa key")
+	}
+	return cert, key, nil
+}
+
+func createCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := newTemplate("vsh ca")
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = writePem(filepath.Join(dir, DefaultCACertFile), "CERTIFICATE", der, 0644); err != nil {
+		return nil, nil, err
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = writePem(filepath.Join(dir, DefaultCAKeyFile), "EC PRIVATE KEY", keyBytes, 0600); err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func issue(dir, name string, tmpl *x509.Certificate, ca *x509.Certificate, caKey *ecdsa.PrivateKey) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
+	return writeKeyPair(dir, name, der, key)
+}
+
+// GenerateCerts creates a self-signed CA in dir (or reuses the one already
+// there), a server certificate valid for hosts and one client certificate per
+// user whose common name is the user name,such as root@10.0.0.1.
+func GenerateCerts(dir string, hosts []string, users []string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	ca, caKey, err := loadCA(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if ca, caKey, err = createCA(dir); err != nil {
+			return err
+		}
+	}
+	if len(hosts) > 0 {
+		tmpl, err := newTemplate(hosts[0])
+		if err != nil {
+			return err
+		}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		for _, host := range hosts {
+			if ip := net.ParseIP(host); ip != nil {
+				tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+			} else {
+				tmpl.DNSNames = append(tmpl.DNSNames, host)
+			}
+		}
+		if err = issue(dir, "server", tmpl, ca, caKey); err != nil {
+			return fmt.Errorf("server cert: %v", err)
+		}
+	}
+	for _, user := range users {
+		tmpl, err := newTemplate(user)
+		if err != nil {
+			return err
+		}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		if err = issue(dir, user, tmpl, ca, caKey); err != nil {
+			return fmt.Errorf("client cert of %s: %v", user, err)
+		}
+	}
+	return nil
+}
+
+// NewTLSConfig loads certFile/keyFile as the local identity and caFile as the
+// pool used to verify the peer. Empty paths are skipped.
+func NewTLSConfig(caFile, certFile, keyFile string) (*tls.Config, *x509.CertPool, error) {
+	conf := &tls.Config{}
+	if len(certFile) > 0 || len(keyFile) > 0 {
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		conf.Certificates = []tls.Certificate{pair}
+	}
+	var pool *x509.CertPool
+	if len(caFile) > 0 {
+		b, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+	}
+	return conf, pool, nil
+}
@@ -15,12 +15,16 @@ const (
 )
 
 type Cluster struct {
-	PubUserName string       `json:"user"`
-	PubPwd      string       `json:"password"`
-	PubPort     int          `json:"port"`
-	PubGroup    string       `json:"group,omitempty"`
-	PubTag      string       `json:"tag,omitempty"`
-	Nodes       []*meta.Node `json:"nodes,omitempty"`
+	PubUserName    string       `json:"user"`
+	PubPwd         string       `json:"password"`
+	PubKeyPath     string       `json:"key_path,omitempty"`
+	PubKey         string       `json:"key,omitempty"`
+	PubPassphrase  string       `json:"passphrase,omitempty"`
+	PubAgentSocket string       `json:"agent_socket,omitempty"`
+	PubPort        int          `json:"port"`
+	PubGroup       string       `json:"group,omitempty"`
+	PubTag         string       `json:"tag,omitempty"`
+	Nodes          []*meta.Node `json:"nodes,omitempty"`
 }
 
 func NewCluster(path string) (*Cluster, error) {
@@ -43,6 +47,28 @@ func NewCluster(path string) (*Cluster, error) {
 		if len(node.Password) == 0 {
 			node.Password = cluster.PubPwd
 		}
+		if len(node.Key) == 0 && len(node.KeyPath) == 0 {
+			node.Key = cluster.PubKey
+			node.KeyPath = cluster.PubKeyPath
+		}
+		if len(node.Passphrase) == 0 {
+			node.Passphrase = cluster.PubPassphrase
+		}
+		if len(node.AgentSocket) == 0 {
+			node.AgentSocket = cluster.PubAgentSocket
+		}
+		// the key is shipped inline so that the server can validate the node
+		if len(node.Key) == 0 && len(node.KeyPath) > 0 {
+			keyPath, err := Expand(node.KeyPath)
+			if err != nil {
+				return nil, err
+			}
+			b, err := ioutil.ReadFile(keyPath)
+			if err != nil {
+				return nil, err
+			}
+			node.Key = string(b)
+		}
 		if len(node.UserName) == 0 {
 			node.UserName = cluster.PubUserName
 		}
@@ -69,12 +95,15 @@ func (c *Cluster) InitRequest() (*pb.UpdateRequest, error) {
 	}
 	for _, v := range c.Nodes {
 		meta := &pb.NodeMeta{
-			Host:     v.Ip,
-			Port:     int32(v.Port),
-			Username: v.UserName,
-			Password: v.Password,
-			Tag:      strings.ToLower(v.Tag),
-			Group:    strings.ToLower(v.GroupName),
+			Host:        v.Ip,
+			Port:        int32(v.Port),
+			Username:    v.UserName,
+			Password:    v.Password,
+			Key:         v.Key,
+			Passphrase:  v.Passphrase,
+			AgentSocket: v.AgentSocket,
+			Tag:         strings.ToLower(v.Tag),
+			Group:       strings.ToLower(v.GroupName),
 		}
 		nodeReq.NodeMetas = append(nodeReq.NodeMetas, meta)
 	}
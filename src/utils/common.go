@@ -6,13 +6,13 @@ import (
 	"meta"
 	"net"
 	"os/user"
+	"path"
 	"path/filepath"
 	"pb"
 	"regexp"
+	"ssh"
 	"strings"
 	"time"
-
-	"golang.org/x/crypto/ssh"
 )
 
 func NewUpdateRequest(req *pb.UpdateRequest) []*meta.Node {
@@ -23,27 +23,24 @@ func NewUpdateRequest(req *pb.UpdateRequest) []*meta.Node {
 		node.Port = int(v.Port)
 		node.UserName = v.Username
 		node.Password = v.Password
+		node.Key = v.Key
+		node.Passphrase = v.Passphrase
+		node.AgentSocket = v.AgentSocket
 		node.Tag = v.Tag
 		node.GroupName = v.Group
 		nodes = append(nodes, node)
 	}
 	return nodes
 }
-func ValidSshServer(node *meta.Node) error {
-	sshConfig := &ssh.ClientConfig{
-		User: node.UserName,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(node.Password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         time.Second * 4,
-	}
-	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", node.Ip, node.Port), sshConfig)
+
+// ValidSshServer logs in node and returns the host key it presented.
+func ValidSshServer(node *meta.Node) (string, error) {
+	client, hostKey, err := ssh.DialWithHostKey(node, time.Second*4)
 	if err != nil {
-		return err
+		return hostKey, err
 	}
 	defer client.Close()
-	return nil
+	return hostKey, nil
 }
 func ValidIpAddr(ipAddress string) bool {
 	ipAddress = strings.Trim(ipAddress, " ")
@@ -55,6 +52,38 @@ func ValidIpAddr(ipAddress string) bool {
 	return false
 }
 
+// MatchHost reports whether ip matches pattern,an ip,a cidr like
+// 10.0.0.0/24 or a glob like 10.0.0.*.
+func MatchHost(pattern string, ip string) bool {
+	if strings.Contains(pattern, "/") {
+		_, ipNet, err := net.ParseCIDR(pattern)
+		if err != nil {
+			return false
+		}
+		addr := net.ParseIP(ip)
+		return addr != nil && ipNet.Contains(addr)
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		ok, err := path.Match(pattern, ip)
+		return err == nil && ok
+	}
+	return strings.Compare(pattern, ip) == 0
+}
+
+// ValidHostPattern checks the cidr or glob syntax of a pattern accepted by
+// MatchHost,anything else is compared literally.
+func ValidHostPattern(pattern string) error {
+	if strings.Contains(pattern, "/") {
+		_, _, err := net.ParseCIDR(pattern)
+		return err
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		_, err := path.Match(pattern, "")
+		return err
+	}
+	return nil
+}
+
 func Dir() (string, error) {
 	currentUser, err := user.Current()
 	if err != nil {